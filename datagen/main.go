@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	dataset "shared/models/Dataset"
+)
+
+func main() {
+	var specPath = flag.String("spec", "specs/lb3.json", "path to the dataset spec (json)")
+	var format = flag.String("format", "csv", "output format: csv or json")
+	var outPath = flag.String("out", "", "output file, stdout when empty")
+	flag.Parse()
+
+	spec, err := dataset.LoadSpec(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := dataset.Generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch *format {
+	case "csv":
+		err = data.WriteCSV(out)
+	case "json":
+		err = data.WriteJSON(out)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
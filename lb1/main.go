@@ -4,6 +4,9 @@ import (
 	// "encoding/json"
 	"fmt"
 	"os"
+	ecdf "shared/models/ECDF"
+	entropy "shared/models/Entropy"
+	randomness "shared/models/Randomness"
 	sq "shared/models/Sequence"
 	sd "shared/models/StatisticalDistribution"
 	"text/tabwriter"
@@ -13,6 +16,11 @@ const (
 	Low  = 1
 	High = 5
 	N    = 12
+	// coverage of the DKW band around the empirical distribution function
+	Confidence = 0.95
+	// draws the randomness battery checks the generator on, and its significance level
+	BatteryN = 10000
+	Alpha    = 0.05
 )
 
 func main() {
@@ -35,6 +43,34 @@ func main() {
 	}
 	writer.Flush()
 	fmt.Println()
+
+	if e, err := ecdf.FromDistribution(sd); err == nil {
+		fmt.Printf("Empirical CDF with a %g%% DKW band:\n", 100*Confidence)
+		e.WriteTable(os.Stdout, Confidence)
+		fmt.Println()
+	}
+
+	// a fair generator spreads evenly over Low..High and each value tells nothing about the next
+	if summary, err := entropy.Summarize(sd, High-Low+1, entropy.Bits); err == nil {
+		fmt.Println("Entropy of the frequencies:")
+		summary.WriteTable(os.Stdout)
+		fmt.Println()
+	}
+	var source = sequence.Source
+	if dependence, err := entropy.Pair(source[:len(source)-1], source[1:], entropy.Bits); err == nil {
+		fmt.Println("Each value against the next:")
+		dependence.WriteTable(os.Stdout)
+		fmt.Println()
+	}
+
+	// twelve values say little about the generator itself, the battery gets a long run of it
+	stream, err := randomness.Integers(sq.Random(BatteryN, Low, High).Source, Low, High)
+	if err != nil {
+		fmt.Println("Randomness battery:", err)
+		return
+	}
+	fmt.Println("Randomness battery for Sequence.Random:")
+	randomness.Run(stream, Alpha).WriteTable(os.Stdout)
 }
 
 // побудувати статистичний розподіл
@@ -2,16 +2,33 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"shared/interfaces"
+	density "shared/models/Density"
 	desmos_constructor "shared/models/Desmos"
+	mixture "shared/models/Mixture"
+	mode_pkg "shared/models/Mode"
+	resample "shared/models/Resample"
+	robust "shared/models/Robust"
+	smp "shared/models/Sample"
 	sq "shared/models/Sequence"
 	sd "shared/models/StatisticalDistribution"
+	simulation "shared/models/Simulation"
 )
 
 const (
 	Low  = 1
 	High = 12
 	N    = 12
+	// bootstrap intervals for the average and the median
+	Confidence   = 0.95
+	Replications = 2000
+	Seed         = 1
+	// gaussian mixtures with up to this many components tell whether several peaks are real
+	MixtureComponents = 3
+	MixtureRestarts   = 10
+	// share cut from each end for the trimmed and winsorized means
+	TrimProportion = 0.1
 )
 
 func int64_to_float32(rx []int) []float32 {
@@ -24,6 +41,18 @@ func int64_to_float32(rx []int) []float32 {
 	return tx
 }
 
+func peakPoints(peaks []mode_pkg.Peak) ([]float32, []float32) {
+	var locations = make([]float32, len(peaks))
+	var heights = make([]float32, len(peaks))
+
+	for i, p := range peaks {
+		locations[i] = float32(p.Location)
+		heights[i] = float32(p.Height)
+	}
+
+	return locations, heights
+}
+
 func main() {
 	sequence := sq.Random(N, Low, High)
 	var castedSequence interfaces.ISequence = sequence
@@ -41,14 +70,69 @@ func main() {
 	fmt.Print(float_desmos.PlotPoints(int64_to_float32(distr.Variants), int64_to_float32(distr.Occurences)), "\n\n")
 
 	fmt.Println("High peak variants mode:")
-	fmt.Print(float_desmos.PlotPoints(mode.GetVariants(mode.High), int64_to_float32(mode.GetOccurences(mode.High))), "\n\n")
+	fmt.Print(float_desmos.PlotPoints(peakPoints(mode.Highest())), "\n\n")
 
 	fmt.Println("Low peak variants mode:")
-	fmt.Print(float_desmos.PlotPoints(mode.GetVariants(mode.Low), int64_to_float32(mode.GetOccurences(mode.Low))), "\n\n")
+	fmt.Print(float_desmos.PlotPoints(peakPoints(mode.Local())), "\n\n")
+
+	mode.WriteTable(os.Stdout)
+	fmt.Println()
+
+	// the same sequence seen as a continuous sample
+	if smooth, err := mode_pkg.FromSample(smp.FromInts(sequence.Source), density.Gaussian, density.Silverman); err == nil {
+		smooth.WriteTable(os.Stdout)
+		fmt.Println()
+	}
+
+	printMixture(sequence.Source)
 
 	fmt.Println("Median:")
 	fmt.Print(median, "\n\n")
 
 	fmt.Println("Avarage:")
 	fmt.Print(avg, "\n\n")
+
+	// the average moves with every extreme value, these estimates do not
+	if summary, err := robust.Summarize(sequence, TrimProportion); err == nil {
+		fmt.Println("Robust location and scale:")
+		summary.WriteTable(os.Stdout)
+		fmt.Println()
+	}
+
+	var config = simulation.Config{Replications: Replications, Seed: Seed}
+	printBootstrap("Avarage", sequence.Source, resample.SequenceAverage, config)
+	printBootstrap("Median", sequence.Source, resample.SequenceMedian, config)
+}
+
+func printMixture(source []int) {
+	var config = simulation.Config{Replications: MixtureRestarts, Seed: Seed}
+	selection, err := mixture.Select(smp.FromInts(source), mixture.Gaussian, MixtureComponents, config)
+	if err != nil {
+		fmt.Println("Gaussian mixtures:", err)
+		return
+	}
+
+	fmt.Println("Gaussian mixtures:")
+	selection.WriteTable(os.Stdout)
+
+	var chosen = selection.Chosen()
+	chosen.WriteTable(os.Stdout)
+
+	fmt.Println("Component of every value:")
+	for i, label := range chosen.Classify() {
+		fmt.Printf("%d:%d ", source[i], label+1)
+	}
+	fmt.Print("\n\n")
+}
+
+func printBootstrap(name string, source []int, statistic resample.Statistic[int], config simulation.Config) {
+	result, err := resample.Bootstrap(source, statistic, config)
+	if err != nil {
+		fmt.Println(name, "bootstrap:", err)
+		return
+	}
+
+	fmt.Println(name, "bootstrap:")
+	result.WriteTable(os.Stdout, Confidence)
+	fmt.Println()
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"log"
+	"os"
 	LinearEquation "shared/models/LinearEquation"
 	NormNoise "shared/models/NormNoise"
+	Outlier "shared/models/Outlier"
 	shared "shared/models/Point"
 	Regression "shared/models/Regression"
 
@@ -19,6 +21,8 @@ const (
 	k    = float32(2.45)
 	b    = float32(3.11)
 	seed = 1100
+	// points whose mahalanobis distance lies beyond this χ² tail are reported
+	outlierAlpha = 0.01
 )
 
 func CombinePoints(x, y []float32) []shared.Point {
@@ -98,6 +102,14 @@ func main() {
 	var linearRegression = Regression.Make(shared.PointArrayToIPointArray(coords))
 	var fns = linearRegression.CalculateRegresionEquations()
 
+	// the regression takes every point as it is, points unusual as an (x, y) pair pull both lines
+	detection, err := Outlier.Mahalanobis(shared.PointArrayToIPointArray(coords), outlierAlpha)
+	if err != nil {
+		log.Println(err)
+	} else {
+		Outlier.WriteTable(os.Stdout, nil, detection)
+	}
+
 	BuildPlot(coords, fns, float32(0.1), slice_length+1)
 }
 
@@ -1,3 +1,6 @@
 module lb4
 
 go 1.25.1
+
+require shared v0.0.0
+replace shared => ../shared
@@ -4,8 +4,16 @@ import (
 	"fmt"
 	math "math"
 	rand "math/rand"
+	"os"
 	"sort"
 	"strings"
+	"text/tabwriter"
+
+	density "shared/models/Density"
+	fit "shared/models/Fit"
+	grouped "shared/models/Grouped"
+	mode_pkg "shared/models/Mode"
+	smp "shared/models/Sample"
 )
 
 type Interval struct {
@@ -94,6 +102,119 @@ func drawASCIIHistogram(intervals []Interval) {
 	fmt.Println(strings.Repeat("=", 70))
 }
 
+// the histogram with a kernel density estimate over it on the same scale, • marks n·f(x) at the
+// middle of every interval
+func drawASCIIDensity(intervals []Interval, kde density.KDE) {
+	var n = float64(len(kde.Values))
+	var curve = make([]float64, len(intervals))
+	maxDensity := 0.0
+	for i, iv := range intervals {
+		curve[i] = n * kde.PDF((iv.Lower+iv.Upper)/2)
+		maxDensity = math.Max(maxDensity, math.Max(curve[i], iv.Density))
+	}
+
+	fmt.Printf("Kernel density: %s kernel, %s bandwidth %.4f\n", kde.Kernel.Name, kde.Rule, kde.Bandwidth)
+	fmt.Println(strings.Repeat("=", 70))
+
+	for i, iv := range intervals {
+		var line = []rune(strings.Repeat("█", int(50*iv.Density/maxDensity)))
+		var marker = int(50 * curve[i] / maxDensity)
+		for len(line) <= marker {
+			line = append(line, ' ')
+		}
+		line[marker] = '•'
+
+		fmt.Printf("[%7.3f, %7.3f) |%-51s %.2f (kde %.2f)\n",
+			iv.Lower, iv.Upper, string(line), iv.Density, curve[i])
+	}
+
+	fmt.Println(strings.Repeat("=", 70))
+}
+
+func toTable(intervals []Interval) grouped.Table {
+	var table = grouped.Table{}
+	for _, iv := range intervals {
+		table = append(table, grouped.Class{Lower: iv.Lower, Upper: iv.Upper, Frequency: int(iv.Frequency)})
+	}
+	return table
+}
+
+// the grouped formulas next to the same statistics of the values themselves, the raw mode is the
+// highest peak of their kernel density
+func printGroupedSummary(values []float64, intervals []Interval) {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	var table = toTable(intervals)
+	var n = float64(len(values))
+	var mean = smp.Mean(values)
+
+	var row = func(name string, groupedValue float64, err error, raw float64) {
+		if err != nil {
+			fmt.Fprintf(writer, " %s\t %v\t %.4f\t\n", name, err, raw)
+			return
+		}
+		fmt.Fprintf(writer, " %s\t %.4f\t %.4f\t\n", name, groupedValue, raw)
+	}
+
+	fmt.Fprintln(writer, " Statistic\t Grouped\t Values\t")
+	row("Mean", table.Mean(), nil, mean)
+	row("Variance", table.Variance(), nil, smp.Variance(values)*(n-1)/n)
+	for _, q := range []float64{0.25, 0.5, 0.75} {
+		groupedValue, err := table.Quantile(q)
+		row(fmt.Sprintf("Quantile %.2f", q), groupedValue, err, smp.Quantile(values, q))
+	}
+
+	var rawMode = math.NaN()
+	if analysis, err := mode_pkg.FromSample(smp.Sample(values), density.Gaussian, density.SheatherJones); err == nil {
+		if peak, err := analysis.Primary(); err == nil {
+			rawMode = peak.Location
+		}
+	}
+	groupedMode, err := table.Mode()
+	row("Mode", groupedMode, err, rawMode)
+
+	var moment = func(k float64) float64 {
+		var sum = 0.0
+		for _, v := range values {
+			sum += math.Pow(v-mean, k)
+		}
+		return sum / n
+	}
+	var m2 = moment(2)
+	row("Skewness", table.Skewness(), nil, moment(3)/math.Pow(m2, 1.5))
+	row("Excess kurtosis", table.Kurtosis(), nil, moment(4)/(m2*m2)-3)
+
+	writer.Flush()
+}
+
+// method of moments next to maximum likelihood, from the values themselves and from their intervals.
+// the grouped log L is that of the interval counts, it does not compare with the other two
+func compareEstimates(values []float64, intervals []Interval, family fit.Family, sheppard bool) {
+	var fits = []fit.Fit{}
+
+	if f, err := fit.MME(smp.Sample(values), family); err == nil {
+		fits = append(fits, f)
+	} else {
+		fmt.Println(err)
+	}
+
+	if f, err := fit.MLE(smp.Sample(values), family); err == nil {
+		fits = append(fits, f)
+	} else {
+		fmt.Println(err)
+	}
+
+	if f, err := fit.MMEGrouped(toTable(intervals), family, sheppard); err == nil {
+		fits = append(fits, f)
+	} else {
+		fmt.Println(err)
+	}
+
+	fit.WriteFits(os.Stdout, fits...)
+}
+
 func main() {
 	var count = N * C
 	var expSlice = []float64{}
@@ -108,7 +229,21 @@ func main() {
 	var normIntervals = getDensityIntervals(normSlice)
 
 	fmt.Println("Exponential distribution:")
-	drawASCIIHistogram(expIntervals);
+	drawASCIIHistogram(expIntervals)
+	if kde, err := density.New(smp.Sample(expSlice), density.Gaussian, density.SheatherJones); err == nil {
+		drawASCIIDensity(expIntervals, kde)
+	}
+	printGroupedSummary(expSlice, expIntervals)
+	fmt.Printf("Estimates of lambda = %d:\n", L)
+	// sheppard's correction assumes a density that vanishes at both ends, the exponential does not
+	compareEstimates(expSlice, expIntervals, fit.Exponential, false)
+
 	fmt.Println("Normal distribution:")
-	drawASCIIHistogram(normIntervals);
+	drawASCIIHistogram(normIntervals)
+	if kde, err := density.New(smp.Sample(normSlice), density.Gaussian, density.SheatherJones); err == nil {
+		drawASCIIDensity(normIntervals, kde)
+	}
+	printGroupedSummary(normSlice, normIntervals)
+	fmt.Printf("Estimates of mu = %d, sigma = %d:\n", M, S)
+	compareEstimates(normSlice, normIntervals, fit.Normal, true)
 }
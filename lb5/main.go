@@ -4,11 +4,31 @@ import (
 	"bufio"
 	"fmt"
 	"math"
+	"math/rand"
 	"os"
 	"strconv"
 	"strings"
+
+	correction "shared/models/Correction"
+	distribution "shared/models/Distribution"
+	divergence "shared/models/Divergence"
+	fit "shared/models/Fit"
+	gof "shared/models/GoodnessOfFit"
+	outlier "shared/models/Outlier"
+	smp "shared/models/Sample"
+	simulation "shared/models/Simulation"
 )
 
+// bins expecting fewer observations than this are merged with their neighbours
+const MinExpected = 5
+
+// the generalized ESD tests for at most this many outliers
+const MaxOutliers = 10
+
+// simulated samples behind the bootstrap p-value of the normal test
+const BootstrapReplications = 2000
+const BootstrapSeed = 1
+
 // GoldsteinCoefficients stores the coefficients for Goldstein approximation
 type GoldsteinCoefficients struct {
 	a, b, c float64
@@ -30,18 +50,36 @@ func main() {
 	fmt.Printf("\nSample size: %d\n", len(sample))
 	fmt.Printf("Significance level α: %.3f\n\n", alpha)
 
-	histogram := createHistogram(sample, 0, 20)
+	histogram := gof.CreateHistogram(sample, 0, 20, gof.Merging{})
 
 	fmt.Println("Histogram:")
 	displayHistogram(histogram)
 	fmt.Println()
 
+	fmt.Println("=== Outlier Screening ===")
+	reportOutliers(sample, alpha)
+	fmt.Println()
+
 	fmt.Println("=== Testing for Normal Distribution ===")
-	testNormalDistribution(histogram, alpha, len(sample))
+	normalP := testNormalDistribution(sample, alpha)
 	fmt.Println()
 
 	fmt.Println("=== Testing for Uniform Distribution ===")
-	testUniformDistribution(histogram, alpha, len(sample))
+	uniformP := testUniformDistribution(sample, alpha)
+	fmt.Println()
+
+	fmt.Println("=== Candidate Distributions (maximum likelihood) ===")
+	ranking := rankCandidates(sample)
+	fmt.Println()
+
+	if len(ranking.Fits) > 0 {
+		fmt.Println("=== Distance from the Fits ===")
+		reportDistances(sample, ranking)
+		fmt.Println()
+	}
+
+	fmt.Println("=== Tests as a Family ===")
+	reportFamily([]string{"Normal", "Uniform"}, []float64{normalP, uniformP}, alpha)
 }
 
 func readInput() ([]int, float64) {
@@ -57,15 +95,18 @@ func readInput() ([]int, float64) {
 	for _, part := range parts {
 		num, err := strconv.Atoi(part)
 		if err != nil {
+			fmt.Printf("Warning: skipping %q, not an integer\n", part)
 			continue
 		}
 		if num < 0 || num > 20 {
+			fmt.Printf("Warning: skipping %d, outside [0, 20]\n", num)
 			continue
 		}
 		sample = append(sample, num)
 	}
 
 	if len(sample) == 0 {
+		fmt.Println("Error: no valid numbers")
 		os.Exit(1)
 	}
 
@@ -87,71 +128,7 @@ func readInput() ([]int, float64) {
 	return sample, alpha
 }
 
-type Histogram struct {
-	Bins       []Bin
-	TotalCount int
-}
-
-type Bin struct {
-	Lower int
-	Upper int
-	Count int
-}
-
-func createHistogram(sample []int, min, max int) *Histogram {
-	freq := make(map[int]int)
-	for _, val := range sample {
-		freq[val]++
-	}
-
-	bins := make([]Bin, 0)
-	for i := min; i <= max; i++ {
-		if freq[i] > 0 {
-			bins = append(bins, Bin{
-				Lower: i,
-				Upper: i,
-				Count: freq[i],
-			})
-		}
-	}
-
-	bins = mergeBins(bins)
-
-	return &Histogram{
-		Bins:       bins,
-		TotalCount: len(sample),
-	}
-}
-
-func mergeBins(bins []Bin) []Bin {
-	if len(bins) == 0 {
-		return bins
-	}
-
-	merged := make([]Bin, 0)
-	current := bins[0]
-
-	for i := 1; i < len(bins); i++ {
-		if current.Count < 5 {
-			current.Upper = bins[i].Upper
-			current.Count += bins[i].Count
-		} else {
-			merged = append(merged, current)
-			current = bins[i]
-		}
-	}
-
-	if len(merged) > 0 && current.Count < 5 {
-		merged[len(merged)-1].Upper = current.Upper
-		merged[len(merged)-1].Count += current.Count
-	} else {
-		merged = append(merged, current)
-	}
-
-	return merged
-}
-
-func displayHistogram(hist *Histogram) {
+func displayHistogram(hist *gof.Histogram) {
 	fmt.Println("Bin Range\t\tCount")
 	fmt.Println("─────────────────────────────")
 	for _, bin := range hist.Bins {
@@ -186,136 +163,275 @@ func calculateChiSquaredCritical(alpha float64, r int) float64 {
 	return chiSquared
 }
 
-func testNormalDistribution(hist *Histogram, alpha float64, n int) {
-	mean := 0.0
-	variance := 0.0
+// returns the parametric bootstrap p-value, the chi-squared one when the bootstrap fails and NaN when the test could not run
+func testNormalDistribution(sample []int, alpha float64) float64 {
+	values := smp.FromInts(sample)
+	mean := smp.Mean(values)
+	stdDev := smp.StdDev(values)
 
-	values := make([]float64, 0, n)
-	for _, bin := range hist.Bins {
-		midpoint := float64(bin.Lower+bin.Upper) / 2.0
-		for j := 0; j < bin.Count; j++ {
-			values = append(values, midpoint)
-		}
+	fmt.Printf("Estimated mean: %.4f\n", mean)
+	fmt.Printf("Estimated standard deviation: %.4f\n", stdDev)
+	fmt.Println()
+
+	// integer values are bins of width 1, the outer bins take the tails of the normal curve
+	lowest, highest := sampleRange(sample)
+	cdf := func(x float64) float64 {
+		return normalCDF((x - mean) / stdDev)
 	}
 
-	for _, v := range values {
-		mean += v
+	result, err := gof.ContinuousFit(values, gof.IntegerEdges(lowest, highest), cdf, 2, gof.ExpectedMerging(MinExpected))
+	reportFit("Normal", result, err, alpha)
+	if err != nil {
+		return math.NaN()
 	}
-	mean /= float64(n)
 
-	for _, v := range values {
-		variance += (v - mean) * (v - mean)
+	// the chi-squared reference ignores that mean and deviation came from the same data,
+	// the bootstrap refits every simulated sample and rebins it exactly like the real one
+	config := simulation.Config{Replications: BootstrapReplications, Seed: BootstrapSeed}
+	bootstrap, err := gof.ParametricBootstrap(values, fitRoundedNormal, binnedChiSquared, config)
+	if err != nil {
+		fmt.Printf("\nBootstrap failed: %v\n", err)
+		return result.PValue
 	}
-	variance /= float64(n - 1)
-	stdDev := math.Sqrt(variance)
 
-	fmt.Printf("Estimated mean: %.4f\n", mean)
-	fmt.Printf("Estimated standard deviation: %.4f\n", stdDev)
+	fmt.Println()
+	fmt.Printf("Parametric bootstrap (%d samples, %d failed to fit):\n", bootstrap.Replications, bootstrap.Failed)
+	fmt.Printf("χ²_critical(α=%.3f) = %.4f\n", alpha, bootstrap.Critical(alpha))
+	fmt.Printf("p-value = %.4f ± %.4f\n", bootstrap.PValue, bootstrap.MCSE)
 	fmt.Println()
 
-	theoreticalFreq := make([]float64, len(hist.Bins))
-	for i, bin := range hist.Bins {
-		lower := float64(bin.Lower) - 0.5
-		upper := float64(bin.Upper) + 0.5
+	// the bootstrap p-value replaces the χ² one, in the verdict and in the family of tests
+	if bootstrap.PValue > alpha {
+		fmt.Printf("p-value > α → Hypothesis ACCEPTED (bootstrap)\n")
+		fmt.Printf("Data follows Normal distribution at significance level α=%.3f\n", alpha)
+	} else {
+		fmt.Printf("p-value ≤ α → Hypothesis REJECTED (bootstrap)\n")
+		fmt.Printf("Data does NOT follow Normal distribution at significance level α=%.3f\n", alpha)
+	}
+	return bootstrap.PValue
+}
 
-		prob := normalCDF((upper-mean)/stdDev) - normalCDF((lower-mean)/stdDev)
-		theoreticalFreq[i] = prob * float64(n)
+// normal fitted to integer data, its draws are rounded like the observations
+type roundedNormal struct {
+	distribution.Normal
+}
+
+func (d roundedNormal) Rand(rnd *rand.Rand) float64 {
+	return math.Round(d.Normal.Rand(rnd))
+}
+
+func fitRoundedNormal(values []float64) (gof.Fitted, error) {
+	fitted, err := gof.FitNormal(values)
+	if err != nil {
+		return nil, err
 	}
+	return roundedNormal{fitted.(distribution.Normal)}, nil
+}
 
-	chiSquared := 0.0
-	fmt.Println("Bin\t\tObserved\tExpected\tContribution")
-	fmt.Println("─────────────────────────────────────────────────────")
-	for i, bin := range hist.Bins {
-		observed := float64(bin.Count)
-		expected := theoreticalFreq[i]
-		contribution := 0.0
-		if expected > 0 {
-			contribution = math.Pow(observed-expected, 2) / expected
-		}
-		chiSquared += contribution
+// the statistic of testNormalDistribution, bins rebuilt over the range of values
+func binnedChiSquared(values []float64, fitted gof.Fitted) float64 {
+	lowest, highest := values[0], values[0]
+	for _, v := range values {
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+	}
 
-		binStr := fmt.Sprintf("[%d-%d]", bin.Lower, bin.Upper)
-		if bin.Lower == bin.Upper {
-			binStr = fmt.Sprintf("[%d]", bin.Lower)
+	edges := gof.IntegerEdges(int(lowest), int(highest))
+	result, _ := gof.ContinuousFit(smp.Sample(values), edges, fitted.CDF, 2, gof.ExpectedMerging(MinExpected))
+	return result.Statistic
+}
+
+// returns the chi-squared p-value, NaN when the test could not run
+func testUniformDistribution(sample []int, alpha float64) float64 {
+	minVal, maxVal := sampleRange(sample)
+	rangeSize := float64(maxVal - minVal + 1)
+
+	freq := make(map[int]int)
+	for _, val := range sample {
+		freq[val]++
+	}
+
+	// every value of the support gets a bin, including the ones never observed
+	categories := make([]gof.Category, 0)
+	for v := minVal; v <= maxVal; v++ {
+		categories = append(categories, gof.Category{
+			Lower:    float64(v) - 0.5,
+			Upper:    float64(v) + 0.5,
+			Observed: freq[v],
+			Expected: float64(len(sample)) / rangeSize,
+		})
+	}
+
+	result, err := gof.Test(categories, 0, gof.ExpectedMerging(MinExpected))
+	reportFit("Uniform", result, err, alpha)
+	if err != nil {
+		return math.NaN()
+	}
+	return result.PValue
+}
+
+// values far from the rest, flagged only: the tests below still see the whole sample. the z-score and the
+// tests of grubbs, dixon and rosner assume normal data, the modified z-score and tukey's fences do not
+func reportOutliers(sample []int, alpha float64) {
+	values := smp.FromInts(sample)
+	detectors := []func() (outlier.Detection, error){
+		func() (outlier.Detection, error) { return outlier.ZScore(values, outlier.ZThreshold) },
+		func() (outlier.Detection, error) { return outlier.ModifiedZScore(values, outlier.ModifiedZThreshold) },
+		func() (outlier.Detection, error) { return outlier.IQRFences(values, outlier.InnerFence) },
+		func() (outlier.Detection, error) { return outlier.Grubbs(values, alpha) },
+		func() (outlier.Detection, error) { return outlier.Dixon(values, alpha) },
+		func() (outlier.Detection, error) {
+			return outlier.GeneralizedESD(values, min(MaxOutliers, len(sample)-2), alpha)
+		},
+	}
+
+	detections := []outlier.Detection{}
+	for _, detect := range detectors {
+		detection, err := detect()
+		if err != nil {
+			fmt.Println("Skipped:", err)
+			continue
 		}
-		fmt.Printf("%-12s\t%d\t\t%.2f\t\t%.4f\n", binStr, bin.Count, expected, contribution)
+		detections = append(detections, detection)
 	}
 
-	degreesOfFreedom := len(hist.Bins) - 2 - 1
+	outlier.WriteTable(os.Stdout, values, detections...)
+}
 
-	if degreesOfFreedom <= 0 {
-		fmt.Println("\nError: Not enough bins for valid test (degrees of freedom <= 0)")
-		return
+// normal and uniform are not the only options, every continuous family is fitted and ranked by AIC.
+// the integers are rounded observations, each one stands for [k - 0.5, k + 0.5)
+func rankCandidates(sample []int) fit.Ranking {
+	ranking, err := fit.AutoFitRounded(smp.FromInts(sample), 1, fit.AIC)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return ranking
 	}
 
-	chiSquaredCritical := calculateChiSquaredCritical(1-alpha, degreesOfFreedom)
+	ranking.WriteTable(os.Stdout)
+	fmt.Printf("Best by AIC: %s\n", ranking.Fits[0].Family)
+	return ranking
+}
 
-	fmt.Println()
-	fmt.Printf("χ²_empirical = %.4f\n", chiSquared)
-	fmt.Printf("χ²_critical(α=%.3f, r=%d) = %.4f\n", alpha, degreesOfFreedom, chiSquaredCritical)
-	fmt.Println()
+// how far the sample's frequencies are from the probabilities every fit gives the integers,
+// the tails beyond the smallest and largest value lumped into the end bins
+func reportDistances(sample []int, ranking fit.Ranking) {
+	lowest, highest := sampleRange(sample)
+	edges := gof.IntegerEdges(lowest, highest)
 
-	if chiSquared < chiSquaredCritical {
-		fmt.Printf("χ²_emp < χ²_crit → Hypothesis ACCEPTED\n")
-		fmt.Printf("Data follows Normal distribution at significance level α=%.3f\n", alpha)
-	} else {
-		fmt.Printf("χ²_emp ≥ χ²_crit → Hypothesis REJECTED\n")
-		fmt.Printf("Data does NOT follow Normal distribution at significance level α=%.3f\n", alpha)
+	observed, err := divergence.FromSample(smp.FromInts(sample), edges)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	names, rows := []string{}, [][]divergence.Distance{}
+	for _, f := range ranking.Fits {
+		expected, err := divergence.FromCDF(f.Model.CDF, edges)
+		if err != nil {
+			continue
+		}
+		names = append(names, "sample vs "+f.Family)
+		rows = append(rows, divergence.All(observed, expected))
 	}
+
+	divergence.WriteTable(os.Stdout, names, rows)
 }
 
-func testUniformDistribution(hist *Histogram, alpha float64, n int) {
-	minVal := hist.Bins[0].Lower
-	maxVal := hist.Bins[len(hist.Bins)-1].Upper
-	rangeSize := float64(maxVal - minVal + 1)
+// the verdicts above each hold at level α alone, together they are judged on adjusted p-values
+func reportFamily(names []string, pValues []float64, alpha float64) {
+	family, familyP := []string{}, []float64{}
+	for i, p := range pValues {
+		if !math.IsNaN(p) {
+			family = append(family, names[i])
+			familyP = append(familyP, p)
+		}
+	}
 
-	theoreticalFreq := make([]float64, len(hist.Bins))
-	for i, bin := range hist.Bins {
-		binSize := float64(bin.Upper - bin.Lower + 1)
-		prob := binSize / rangeSize
-		theoreticalFreq[i] = prob * float64(n)
+	if len(familyP) == 0 {
+		fmt.Println("No test produced a p-value")
+		return
 	}
 
-	chiSquared := 0.0
+	results := []correction.Result{}
+	for _, method := range correction.Methods {
+		result, err := correction.Apply(familyP, method, alpha)
+		if err != nil {
+			fmt.Printf("%s: %v\n", method, err)
+			return
+		}
+		results = append(results, result)
+	}
+
+	fmt.Printf("Adjusted p-values (α=%.3f):\n", alpha)
+	correction.WriteTable(os.Stdout, family, results...)
+}
+
+func reportFit(name string, result gof.Result, err error, alpha float64) {
 	fmt.Println("Bin\t\tObserved\tExpected\tContribution")
 	fmt.Println("─────────────────────────────────────────────────────")
-	for i, bin := range hist.Bins {
-		observed := float64(bin.Count)
-		expected := theoreticalFreq[i]
+	for _, group := range result.Groups {
+		observed := float64(group.Observed[0])
+		expected := group.Expected[0]
 		contribution := 0.0
 		if expected > 0 {
 			contribution = math.Pow(observed-expected, 2) / expected
 		}
-		chiSquared += contribution
 
-		binStr := fmt.Sprintf("[%d-%d]", bin.Lower, bin.Upper)
-		if bin.Lower == bin.Upper {
-			binStr = fmt.Sprintf("[%d]", bin.Lower)
-		}
-		fmt.Printf("%-12s\t%d\t\t%.2f\t\t%.4f\n", binStr, bin.Count, expected, contribution)
+		fmt.Printf("%-12s\t%d\t\t%.2f\t\t%.4f\n", binLabel(group.Lower, group.Upper), group.Observed[0], expected, contribution)
 	}
 
-	degreesOfFreedom := len(hist.Bins) - 0 - 1
+	for _, merge := range result.Merges {
+		fmt.Printf("Merged %s: expected %.2f after merging (minimum %d)\n", binLabel(merge.Lower, merge.Upper), merge.Weight, MinExpected)
+	}
 
-	if degreesOfFreedom <= 0 {
-		fmt.Println("\nError: Not enough bins for valid test (degrees of freedom <= 0)")
+	if err != nil {
+		fmt.Printf("\nError: Not enough bins for valid test (degrees of freedom = %d)\n", result.DF)
 		return
 	}
 
-	chiSquaredCritical := calculateChiSquaredCritical(1-alpha, degreesOfFreedom)
+	chiSquaredCritical := calculateChiSquaredCritical(1-alpha, result.DF)
 
 	fmt.Println()
-	fmt.Printf("χ²_empirical = %.4f\n", chiSquared)
-	fmt.Printf("χ²_critical(α=%.3f, r=%d) = %.4f\n", alpha, degreesOfFreedom, chiSquaredCritical)
+	fmt.Printf("r = %d bins - %d estimated - 1 = %d\n", len(result.Groups), result.Estimated, result.DF)
+	fmt.Printf("χ²_empirical = %.4f\n", result.Statistic)
+	fmt.Printf("χ²_critical(α=%.3f, r=%d) = %.4f\n", alpha, result.DF, chiSquaredCritical)
+	fmt.Printf("p-value = %.4g\n", result.PValue)
 	fmt.Println()
 
-	if chiSquared < chiSquaredCritical {
+	if result.Statistic < chiSquaredCritical {
 		fmt.Printf("χ²_emp < χ²_crit → Hypothesis ACCEPTED\n")
-		fmt.Printf("Data follows Uniform distribution at significance level α=%.3f\n", alpha)
+		fmt.Printf("Data follows %s distribution at significance level α=%.3f\n", name, alpha)
 	} else {
 		fmt.Printf("χ²_emp ≥ χ²_crit → Hypothesis REJECTED\n")
-		fmt.Printf("Data does NOT follow Uniform distribution at significance level α=%.3f\n", alpha)
+		fmt.Printf("Data does NOT follow %s distribution at significance level α=%.3f\n", name, alpha)
+	}
+}
+
+// bins are [k - 0.5, m + 0.5) around the integers k..m, infinite bounds are the tails
+func binLabel(lower, upper float64) string {
+	switch {
+	case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+		return "[all]"
+	case math.IsInf(lower, -1):
+		return fmt.Sprintf("[≤%d]", int(math.Floor(upper-0.5)))
+	case math.IsInf(upper, 1):
+		return fmt.Sprintf("[≥%d]", int(math.Ceil(lower+0.5)))
+	}
+
+	first, last := int(math.Ceil(lower+0.5)), int(math.Floor(upper-0.5))
+	if first == last {
+		return fmt.Sprintf("[%d]", first)
+	}
+	return fmt.Sprintf("[%d-%d]", first, last)
+}
+
+func sampleRange(sample []int) (int, int) {
+	lowest, highest := sample[0], sample[0]
+	for _, v := range sample {
+		lowest = min(lowest, v)
+		highest = max(highest, v)
 	}
+	return lowest, highest
 }
 
 func normalCDF(x float64) float64 {
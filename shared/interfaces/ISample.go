@@ -0,0 +1,5 @@
+package interfaces
+
+type ISample interface {
+	GetValues() []float64
+}
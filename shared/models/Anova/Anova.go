@@ -0,0 +1,261 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"text/tabwriter"
+
+	distribution "shared/models/Distribution"
+	sample "shared/models/Sample"
+)
+
+type GroupSummary struct {
+	N        int
+	Mean     float64
+	Variance float64
+}
+
+// one line of the sum-of-squares table
+type Source struct {
+	Name string
+	SS   float64
+	DF   float64
+	MS   float64
+}
+
+type Table struct {
+	Groups     []GroupSummary
+	Between    Source
+	Within     Source
+	Total      Source
+	F          float64
+	PValue     float64
+	EtaSquared float64
+}
+
+// pairwise difference Mean(I) − Mean(J) with its simultaneous confidence interval and adjusted p-value
+type Comparison struct {
+	I          int
+	J          int
+	Difference float64
+	Lower      float64
+	Upper      float64
+	Statistic  float64
+	PValue     float64
+}
+
+// result of a homogeneity of variance test
+type HomogeneityResult struct {
+	Statistic float64
+	DF1       float64
+	DF2       float64
+	PValue    float64
+}
+
+// spread around the group mean (levene) or median (brown–forsythe)
+type Center int
+
+const (
+	CenterMean Center = iota
+	CenterMedian
+)
+
+func OneWay(groups ...interfaces.ISample) (Table, error) {
+	if len(groups) < 2 {
+		return Table{}, errors.New("anova: at least two groups are needed")
+	}
+
+	var table = Table{Groups: make([]GroupSummary, len(groups))}
+	var total, n = 0.0, 0
+
+	for i, group := range groups {
+		var values = group.GetValues()
+		if len(values) == 0 {
+			return Table{}, fmt.Errorf("anova: group %d is empty", i)
+		}
+
+		table.Groups[i] = GroupSummary{N: len(values), Mean: sample.Mean(values), Variance: math.NaN()}
+		if len(values) > 1 {
+			table.Groups[i].Variance = sample.Variance(values)
+		}
+
+		for _, v := range values {
+			total += v
+		}
+		n += len(values)
+	}
+
+	var grand = total / float64(n)
+	var ssBetween, ssWithin = 0.0, 0.0
+
+	for i, group := range groups {
+		var summary = table.Groups[i]
+		ssBetween += float64(summary.N) * (summary.Mean - grand) * (summary.Mean - grand)
+
+		for _, v := range group.GetValues() {
+			ssWithin += (v - summary.Mean) * (v - summary.Mean)
+		}
+	}
+
+	var k = len(groups)
+	if n-k <= 0 {
+		return Table{}, errors.New("anova: no within-group degrees of freedom")
+	}
+
+	table.Between = Source{Name: "Between", SS: ssBetween, DF: float64(k - 1), MS: ssBetween / float64(k-1)}
+	table.Within = Source{Name: "Within", SS: ssWithin, DF: float64(n - k), MS: ssWithin / float64(n-k)}
+	table.Total = Source{Name: "Total", SS: ssBetween + ssWithin, DF: float64(n - 1), MS: (ssBetween + ssWithin) / float64(n-1)}
+	table.F = table.Between.MS / table.Within.MS
+	table.PValue = distribution.FisherF{D1: table.Between.DF, D2: table.Within.DF}.Survival(table.F)
+	table.EtaSquared = ssBetween / table.Total.SS
+
+	return table, nil
+}
+
+// tukey–kramer honestly significant differences, valid for unequal group sizes
+func (t Table) TukeyHSD(confidence float64) []Comparison {
+	var k = float64(len(t.Groups))
+	var reference = distribution.StudentizedRange{K: k, Nu: t.Within.DF}
+	var critical = reference.Quantile(confidence)
+
+	return t.pairs(func(gi, gj GroupSummary) Comparison {
+		var diff = gi.Mean - gj.Mean
+		var se = math.Sqrt(t.Within.MS / 2 * (1/float64(gi.N) + 1/float64(gj.N)))
+		var q = math.Abs(diff) / se
+
+		return Comparison{
+			Difference: diff,
+			Lower:      diff - critical*se,
+			Upper:      diff + critical*se,
+			Statistic:  q,
+			PValue:     reference.Survival(q),
+		}
+	})
+}
+
+// pairwise t-tests on the pooled within-group variance, p-values and intervals adjusted for k(k−1)/2 comparisons
+func (t Table) Bonferroni(confidence float64) []Comparison {
+	var k = len(t.Groups)
+	var m = float64(k * (k - 1) / 2)
+	var reference = distribution.StudentT{Nu: t.Within.DF}
+	var critical = reference.Quantile(1 - (1-confidence)/(2*m))
+
+	return t.pairs(func(gi, gj GroupSummary) Comparison {
+		var diff = gi.Mean - gj.Mean
+		var se = math.Sqrt(t.Within.MS * (1/float64(gi.N) + 1/float64(gj.N)))
+		var statistic = diff / se
+
+		return Comparison{
+			Difference: diff,
+			Lower:      diff - critical*se,
+			Upper:      diff + critical*se,
+			Statistic:  statistic,
+			PValue:     math.Min(1, 2*m*reference.Survival(math.Abs(statistic))),
+		}
+	})
+}
+
+func (t Table) pairs(compare func(gi, gj GroupSummary) Comparison) []Comparison {
+	var comparisons = []Comparison{}
+
+	for i := 0; i < len(t.Groups); i++ {
+		for j := i + 1; j < len(t.Groups); j++ {
+			var c = compare(t.Groups[i], t.Groups[j])
+			c.I, c.J = i, j
+			comparisons = append(comparisons, c)
+		}
+	}
+
+	return comparisons
+}
+
+// anova on absolute deviations from each group's center
+func Levene(center Center, groups ...interfaces.ISample) (HomogeneityResult, error) {
+	var deviations = make([]interfaces.ISample, len(groups))
+
+	for i, group := range groups {
+		var values = group.GetValues()
+		var c = sample.Mean(values)
+		if center == CenterMedian {
+			c = sample.Median(values)
+		}
+
+		var z = make(sample.Sample, len(values))
+		for j, v := range values {
+			z[j] = math.Abs(v - c)
+		}
+		deviations[i] = z
+	}
+
+	table, err := OneWay(deviations...)
+	if err != nil {
+		return HomogeneityResult{}, err
+	}
+
+	return HomogeneityResult{Statistic: table.F, DF1: table.Between.DF, DF2: table.Within.DF, PValue: table.PValue}, nil
+}
+
+// bartlett's K², sensitive to non-normality, chi-squared with k−1 degrees of freedom
+func Bartlett(groups ...interfaces.ISample) (HomogeneityResult, error) {
+	if len(groups) < 2 {
+		return HomogeneityResult{}, errors.New("bartlett: at least two groups are needed")
+	}
+
+	var k = float64(len(groups))
+	var n, pooled, logSum, invSum = 0.0, 0.0, 0.0, 0.0
+
+	for i, group := range groups {
+		var values = group.GetValues()
+		if len(values) < 2 {
+			return HomogeneityResult{}, fmt.Errorf("bartlett: group %d needs at least two values", i)
+		}
+
+		var df = float64(len(values) - 1)
+		var variance = sample.Variance(values)
+		n += float64(len(values))
+		pooled += df * variance
+		logSum += df * math.Log(variance)
+		invSum += 1 / df
+	}
+
+	pooled /= n - k
+	var statistic = ((n-k)*math.Log(pooled) - logSum) / (1 + (invSum-1/(n-k))/(3*(k-1)))
+
+	return HomogeneityResult{
+		Statistic: statistic,
+		DF1:       k - 1,
+		DF2:       math.Inf(1),
+		PValue:    distribution.ChiSquared{K: k - 1}.Survival(statistic),
+	}, nil
+}
+
+func (t Table) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Source\t SS\t df\t MS\t F\t p-value\t")
+	fmt.Fprintf(writer, " %s\t %.4f\t %g\t %.4f\t %.4f\t %.4g\t\n", t.Between.Name, t.Between.SS, t.Between.DF, t.Between.MS, t.F, t.PValue)
+	fmt.Fprintf(writer, " %s\t %.4f\t %g\t %.4f\t\t\t\n", t.Within.Name, t.Within.SS, t.Within.DF, t.Within.MS)
+	fmt.Fprintf(writer, " %s\t %.4f\t %g\t\t\t\t\n", t.Total.Name, t.Total.SS, t.Total.DF)
+	writer.Flush()
+
+	_, err := fmt.Fprintf(w, "η² = %.4f\n", t.EtaSquared)
+	return err
+}
+
+func WriteComparisons(w io.Writer, comparisons []Comparison) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Pair\t Difference\t Lower\t Upper\t p-value\t")
+	for _, c := range comparisons {
+		fmt.Fprintf(writer, " %d-%d\t %.4f\t %.4f\t %.4f\t %.4g\t\n", c.I, c.J, c.Difference, c.Lower, c.Upper, c.PValue)
+	}
+
+	return writer.Flush()
+}
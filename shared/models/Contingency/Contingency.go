@@ -0,0 +1,318 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	distribution "shared/models/Distribution"
+	hypothesis "shared/models/Hypothesis"
+	sample "shared/models/Sample"
+)
+
+// Counts[i][j] is the number of pairs with x = RowLabels[i] and y = ColumnLabels[j]
+type Table struct {
+	RowLabels    []float64
+	ColumnLabels []float64
+	Counts       [][]int
+	RowTotals    []int
+	ColumnTotals []int
+	Total        int
+}
+
+type Result struct {
+	Statistic float64
+	DF        float64
+	PValue    float64
+}
+
+type FisherResult struct {
+	PValue      float64
+	OddsRatio   float64
+	Alternative hypothesis.Alternative
+}
+
+// cross-tabulation of paired observations, categories are the distinct values in ascending order
+func CrossTabulate(x, y interfaces.ISample) (Table, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if len(xs) != len(ys) {
+		return Table{}, errors.New("contingency: x and y must have the same length")
+	}
+
+	var rows, columns = categories(xs), categories(ys)
+	var counts = make([][]int, len(rows))
+	for i := range counts {
+		counts[i] = make([]int, len(columns))
+	}
+
+	var rowIndex, columnIndex = indexOf(rows), indexOf(columns)
+	for i := range xs {
+		counts[rowIndex[xs[i]]][columnIndex[ys[i]]]++
+	}
+
+	table, err := FromCounts(counts)
+	if err != nil {
+		return Table{}, err
+	}
+	table.RowLabels, table.ColumnLabels = rows, columns
+
+	return table, nil
+}
+
+// table over already tabulated counts, labels are 0..r-1 and 0..c-1
+func FromCounts(counts [][]int) (Table, error) {
+	var table = Table{Counts: counts, RowTotals: make([]int, len(counts))}
+	if len(counts) > 0 {
+		table.ColumnTotals = make([]int, len(counts[0]))
+	}
+
+	for i, row := range counts {
+		if len(row) != len(table.ColumnTotals) {
+			return Table{}, fmt.Errorf("contingency: row %d has %d columns, row 0 has %d", i, len(row), len(table.ColumnTotals))
+		}
+		for j, c := range row {
+			if c < 0 {
+				return Table{}, fmt.Errorf("contingency: count %d at row %d, column %d is negative", c, i, j)
+			}
+			table.RowTotals[i] += c
+			table.ColumnTotals[j] += c
+			table.Total += c
+		}
+	}
+
+	table.RowLabels = make([]float64, len(table.RowTotals))
+	for i := range table.RowLabels {
+		table.RowLabels[i] = float64(i)
+	}
+	table.ColumnLabels = make([]float64, len(table.ColumnTotals))
+	for j := range table.ColumnLabels {
+		table.ColumnLabels[j] = float64(j)
+	}
+
+	if table.Total == 0 {
+		return Table{}, errors.New("contingency: the table is empty")
+	}
+
+	return table, nil
+}
+
+// maps every value to the index of its bin, edges are ascending bin boundaries.
+// values below the first edge go to bin 0 and values at or above the last edge go to the last bin
+func Discretize(x interfaces.ISample, edges []float64) sample.Sample {
+	var values = x.GetValues()
+	var bins = make(sample.Sample, len(values))
+
+	for i, v := range values {
+		var idx = sort.SearchFloat64s(edges, v)
+		if idx < len(edges) && edges[idx] == v {
+			idx++
+		}
+		bins[i] = float64(max(0, min(idx-1, len(edges)-2)))
+	}
+
+	return bins
+}
+
+// count equal-width edges spanning the sample, ready for Discretize
+func EqualWidthEdges(x interfaces.ISample, count int) ([]float64, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("contingency: at least one bin is needed, got %d", count)
+	}
+	var sorted = sample.Sorted(x.GetValues())
+	if len(sorted) == 0 {
+		return nil, errors.New("contingency: no values")
+	}
+
+	var low, high = sorted[0], sorted[len(sorted)-1]
+	var edges = make([]float64, count+1)
+
+	for i := range edges {
+		edges[i] = low + (high-low)*float64(i)/float64(count)
+	}
+
+	return edges, nil
+}
+
+func (t Table) Expected() [][]float64 {
+	var expected = make([][]float64, len(t.Counts))
+	for i := range expected {
+		expected[i] = make([]float64, len(t.ColumnTotals))
+		for j := range expected[i] {
+			expected[i][j] = float64(t.RowTotals[i]) * float64(t.ColumnTotals[j]) / float64(t.Total)
+		}
+	}
+	return expected
+}
+
+func (t Table) DF() float64 {
+	return float64((len(t.RowTotals) - 1) * (len(t.ColumnTotals) - 1))
+}
+
+// pearson chi-squared test of independence. yates' continuity correction applies to 2×2 tables only
+func (t Table) ChiSquare(yates bool) Result {
+	var expected = t.Expected()
+	var correct = yates && t.is2x2()
+	var statistic = 0.0
+
+	for i, row := range t.Counts {
+		for j, c := range row {
+			var diff = math.Abs(float64(c) - expected[i][j])
+			if correct {
+				diff = math.Max(0, diff-0.5)
+			}
+			statistic += diff * diff / expected[i][j]
+		}
+	}
+
+	return Result{Statistic: statistic, DF: t.DF(), PValue: distribution.ChiSquared{K: t.DF()}.Survival(statistic)}
+}
+
+// likelihood ratio test G = 2 Σ O ln(O / E), empty cells contribute nothing
+func (t Table) GTest() Result {
+	var expected = t.Expected()
+	var statistic = 0.0
+
+	for i, row := range t.Counts {
+		for j, c := range row {
+			if c > 0 {
+				statistic += 2 * float64(c) * math.Log(float64(c)/expected[i][j])
+			}
+		}
+	}
+
+	return Result{Statistic: statistic, DF: t.DF(), PValue: distribution.ChiSquared{K: t.DF()}.Survival(statistic)}
+}
+
+// fisher's exact test for 2×2 tables through the hypergeometric distribution of the top-left cell.
+// Greater means a positive association (odds ratio above one)
+func (t Table) FisherExact(alternative hypothesis.Alternative) (FisherResult, error) {
+	if !t.is2x2() {
+		return FisherResult{}, errors.New("fisher: only 2×2 tables are supported")
+	}
+
+	var a, b = t.Counts[0][0], t.Counts[0][1]
+	var c, d = t.Counts[1][0], t.Counts[1][1]
+	var r1, c1, n = t.RowTotals[0], t.ColumnTotals[0], t.Total
+	var low, high = max(0, r1+c1-n), min(r1, c1)
+
+	var probability = func(x int) float64 {
+		return math.Exp(logChoose(c1, x) + logChoose(n-c1, r1-x) - logChoose(n, r1))
+	}
+
+	var observed = probability(a)
+	var pValue = 0.0
+	for x := low; x <= high; x++ {
+		var p = probability(x)
+		switch alternative {
+		case hypothesis.Greater:
+			if x >= a {
+				pValue += p
+			}
+		case hypothesis.Less:
+			if x <= a {
+				pValue += p
+			}
+		default:
+			// tables at most as likely as the observed one, with a relative tolerance for rounding
+			if p <= observed*(1+1e-7) {
+				pValue += p
+			}
+		}
+	}
+
+	var oddsRatio = float64(a*d) / float64(b*c)
+	if b*c == 0 {
+		oddsRatio = math.Inf(1)
+		if a*d == 0 {
+			oddsRatio = math.NaN()
+		}
+	}
+
+	return FisherResult{PValue: math.Min(1, pValue), OddsRatio: oddsRatio, Alternative: alternative}, nil
+}
+
+// cramér's V = √(χ² / (n·(min(r, c) − 1)))
+func (t Table) CramersV() float64 {
+	var k = min(len(t.RowTotals), len(t.ColumnTotals))
+	return math.Sqrt(t.ChiSquare(false).Statistic / (float64(t.Total) * float64(k-1)))
+}
+
+// φ = √(χ² / n), signed (ad − bc) / √(r1·r2·c1·c2) for 2×2 tables
+func (t Table) Phi() float64 {
+	if t.is2x2() {
+		var ad = float64(t.Counts[0][0]) * float64(t.Counts[1][1])
+		var bc = float64(t.Counts[0][1]) * float64(t.Counts[1][0])
+		var margins = float64(t.RowTotals[0]) * float64(t.RowTotals[1]) * float64(t.ColumnTotals[0]) * float64(t.ColumnTotals[1])
+		return (ad - bc) / math.Sqrt(margins)
+	}
+
+	return math.Sqrt(t.ChiSquare(false).Statistic / float64(t.Total))
+}
+
+// pearson's contingency coefficient C = √(χ² / (χ² + n))
+func (t Table) ContingencyCoefficient() float64 {
+	var chi = t.ChiSquare(false).Statistic
+	return math.Sqrt(chi / (chi + float64(t.Total)))
+}
+
+func (t Table) is2x2() bool {
+	return len(t.RowTotals) == 2 && len(t.ColumnTotals) == 2
+}
+
+func (t Table) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprint(writer, " x \\ y\t")
+	for _, label := range t.ColumnLabels {
+		fmt.Fprintf(writer, " %g\t", label)
+	}
+	fmt.Fprintln(writer, " Total\t")
+
+	for i, row := range t.Counts {
+		fmt.Fprintf(writer, " %g\t", t.RowLabels[i])
+		for _, c := range row {
+			fmt.Fprintf(writer, " %d\t", c)
+		}
+		fmt.Fprintf(writer, " %d\t\n", t.RowTotals[i])
+	}
+
+	fmt.Fprint(writer, " Total\t")
+	for _, c := range t.ColumnTotals {
+		fmt.Fprintf(writer, " %d\t", c)
+	}
+	fmt.Fprintf(writer, " %d\t\n", t.Total)
+
+	return writer.Flush()
+}
+
+func categories(values []float64) []float64 {
+	var sorted = sample.Sorted(values)
+	var unique = []float64{}
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func indexOf(labels []float64) map[float64]int {
+	var index = make(map[float64]int, len(labels))
+	for i, label := range labels {
+		index[label] = i
+	}
+	return index
+}
+
+func logChoose(n, k int) float64 {
+	ln, _ := math.Lgamma(float64(n + 1))
+	lk, _ := math.Lgamma(float64(k + 1))
+	lnk, _ := math.Lgamma(float64(n - k + 1))
+	return ln - lk - lnk
+}
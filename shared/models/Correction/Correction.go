@@ -0,0 +1,171 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+type Method int
+
+const (
+	// family-wise error rate
+	Bonferroni Method = iota
+	Holm
+	Hochberg
+	// false discovery rate
+	BenjaminiHochberg
+	BenjaminiYekutieli
+)
+
+var Methods = []Method{Bonferroni, Holm, Hochberg, BenjaminiHochberg, BenjaminiYekutieli}
+
+func (m Method) String() string {
+	switch m {
+	case Bonferroni:
+		return "Bonferroni"
+	case Holm:
+		return "Holm"
+	case Hochberg:
+		return "Hochberg"
+	case BenjaminiHochberg:
+		return "Benjamini–Hochberg"
+	case BenjaminiYekutieli:
+		return "Benjamini–Yekutieli"
+	default:
+		return fmt.Sprintf("Method(%d)", int(m))
+	}
+}
+
+type Result struct {
+	Method   Method
+	Alpha    float64
+	PValues  []float64
+	Adjusted []float64
+	// Rejected[i] when Adjusted[i] ≤ Alpha
+	Rejected   []bool
+	Rejections int
+}
+
+// adjusted p-values in the order of pValues, the same as R's p.adjust
+func Adjust(pValues []float64, method Method) ([]float64, error) {
+	var n = len(pValues)
+	for i, p := range pValues {
+		if math.IsNaN(p) || p < 0 || p > 1 {
+			return nil, fmt.Errorf("correction: p-value %d is %g", i, p)
+		}
+	}
+
+	var adjusted = make([]float64, n)
+	var m = float64(n)
+
+	switch method {
+	case Bonferroni:
+		for i, p := range pValues {
+			adjusted[i] = math.Min(1, m*p)
+		}
+
+	case Holm:
+		// step-down from the smallest p-value, (n − rank + 1)·p kept non-decreasing
+		var running = 0.0
+		for rank, i := range order(pValues, false) {
+			running = math.Max(running, (m-float64(rank))*pValues[i])
+			adjusted[i] = math.Min(1, running)
+		}
+
+	case Hochberg, BenjaminiHochberg, BenjaminiYekutieli:
+		// step-up from the largest p-value, kept non-increasing
+		var harmonic = 1.0
+		if method == BenjaminiYekutieli {
+			harmonic = 0
+			for k := 1; k <= n; k++ {
+				harmonic += 1 / float64(k)
+			}
+		}
+
+		var running = math.Inf(1)
+		for step, i := range order(pValues, true) {
+			var rank = m - float64(step)
+			var factor = m - rank + 1
+			if method != Hochberg {
+				factor = harmonic * m / rank
+			}
+			running = math.Min(running, factor*pValues[i])
+			adjusted[i] = math.Min(1, running)
+		}
+
+	default:
+		return nil, fmt.Errorf("correction: unknown method %d", method)
+	}
+
+	return adjusted, nil
+}
+
+// adjusts a family of p-values and decides every hypothesis at level alpha
+func Apply(pValues []float64, method Method, alpha float64) (Result, error) {
+	if len(pValues) == 0 {
+		return Result{}, errors.New("correction: no p-values")
+	}
+
+	adjusted, err := Adjust(pValues, method)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result = Result{Method: method, Alpha: alpha, PValues: pValues, Adjusted: adjusted, Rejected: make([]bool, len(pValues))}
+	for i, p := range adjusted {
+		if p <= alpha {
+			result.Rejected[i] = true
+			result.Rejections++
+		}
+	}
+
+	return result, nil
+}
+
+// one row per hypothesis, raw p-value then the adjusted one and the decision of every result
+func WriteTable(w io.Writer, names []string, results ...Result) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprint(writer, " Hypothesis\t p-value\t")
+	for _, r := range results {
+		fmt.Fprintf(writer, " %s\t", r.Method)
+	}
+	fmt.Fprintln(writer)
+
+	for i, name := range names {
+		fmt.Fprintf(writer, " %s\t %.4g\t", name, results[0].PValues[i])
+		for _, r := range results {
+			var verdict = "accept"
+			if r.Rejected[i] {
+				verdict = "reject"
+			}
+			fmt.Fprintf(writer, " %.4g (%s)\t", r.Adjusted[i], verdict)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	return writer.Flush()
+}
+
+// indices of pValues by ascending (or descending) p-value, ties keep their order
+func order(pValues []float64, descending bool) []int {
+	var idx = make([]int, len(pValues))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.SliceStable(idx, func(a, b int) bool {
+		if descending {
+			return pValues[idx[a]] > pValues[idx[b]]
+		}
+		return pValues[idx[a]] < pValues[idx[b]]
+	})
+
+	return idx
+}
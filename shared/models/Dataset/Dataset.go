@@ -0,0 +1,303 @@
+package shared
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+
+	point_pkg "shared/models/Point"
+)
+
+// x design kinds
+const (
+	XLinear  = "linear"
+	XUniform = "uniform"
+	XNormal  = "normal"
+)
+
+// true model kinds
+const (
+	ModelLinear     = "linear"
+	ModelPolynomial = "polynomial"
+)
+
+// noise kinds. half-normal is what NormNoise generates
+const (
+	NoiseNone       = "none"
+	NoiseNormal     = "normal"
+	NoiseHalfNormal = "half-normal"
+	NoiseUniform    = "uniform"
+)
+
+// linear: Start + i*Step, uniform: U[Low, High), normal: N(Mean, Deviation)
+type XDesign struct {
+	Kind      string  `json:"kind"`
+	Start     float64 `json:"start,omitempty"`
+	Step      float64 `json:"step,omitempty"`
+	Low       float64 `json:"low,omitempty"`
+	High      float64 `json:"high,omitempty"`
+	Mean      float64 `json:"mean,omitempty"`
+	Deviation float64 `json:"deviation,omitempty"`
+}
+
+// linear: K*x + B, polynomial: Coefficients[0] + Coefficients[1]*x + ...
+type Model struct {
+	Kind         string    `json:"kind"`
+	K            float64   `json:"k,omitempty"`
+	B            float64   `json:"b,omitempty"`
+	Coefficients []float64 `json:"coefficients,omitempty"`
+}
+
+// normal and half-normal use Deviation, uniform adds U[Low, High)
+type Noise struct {
+	Kind      string  `json:"kind"`
+	Deviation float64 `json:"deviation,omitempty"`
+	Low       float64 `json:"low,omitempty"`
+	High      float64 `json:"high,omitempty"`
+}
+
+// Count rows (or Fraction of rows when Count is 0) get y shifted by ±Magnitude
+type Outliers struct {
+	Count     int     `json:"count,omitempty"`
+	Fraction  float64 `json:"fraction,omitempty"`
+	Magnitude float64 `json:"magnitude,omitempty"`
+}
+
+type Spec struct {
+	Name     string   `json:"name,omitempty"`
+	Rows     int      `json:"rows"`
+	Seed     int64    `json:"seed"`
+	X        XDesign  `json:"x"`
+	Model    Model    `json:"model"`
+	Noise    Noise    `json:"noise"`
+	Outliers Outliers `json:"outliers"`
+}
+
+type Row struct {
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Outlier bool    `json:"outlier"`
+}
+
+type Dataset struct {
+	Spec Spec  `json:"spec"`
+	Rows []Row `json:"rows"`
+}
+
+func ParseSpec(data []byte) (Spec, error) {
+	var spec Spec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return Spec{}, fmt.Errorf("parse dataset spec: %w", err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return Spec{}, err
+	}
+
+	return spec, nil
+}
+
+func LoadSpec(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, err
+	}
+
+	return ParseSpec(data)
+}
+
+func (s Spec) Validate() error {
+	if s.Rows <= 0 {
+		return errors.New("dataset spec: rows must be positive")
+	}
+
+	switch s.X.Kind {
+	case XLinear:
+		if s.X.Step == 0 {
+			return errors.New("dataset spec: linear x design needs a non-zero step")
+		}
+	case XUniform:
+		if s.X.High <= s.X.Low {
+			return errors.New("dataset spec: uniform x design needs high > low")
+		}
+	case XNormal:
+		if s.X.Deviation < 0 {
+			return errors.New("dataset spec: normal x design needs non-negative deviation")
+		}
+	default:
+		return fmt.Errorf("dataset spec: unknown x design %q", s.X.Kind)
+	}
+
+	switch s.Model.Kind {
+	case ModelLinear:
+	case ModelPolynomial:
+		if len(s.Model.Coefficients) == 0 {
+			return errors.New("dataset spec: polynomial model needs coefficients")
+		}
+	default:
+		return fmt.Errorf("dataset spec: unknown model %q", s.Model.Kind)
+	}
+
+	switch s.Noise.Kind {
+	case "", NoiseNone:
+	case NoiseNormal, NoiseHalfNormal:
+		if s.Noise.Deviation < 0 {
+			return errors.New("dataset spec: noise deviation must be non-negative")
+		}
+	case NoiseUniform:
+		if s.Noise.High < s.Noise.Low {
+			return errors.New("dataset spec: uniform noise needs high >= low")
+		}
+	default:
+		return fmt.Errorf("dataset spec: unknown noise %q", s.Noise.Kind)
+	}
+
+	if s.Outliers.Count < 0 || s.Outliers.Fraction < 0 || s.Outliers.Fraction > 1 {
+		return errors.New("dataset spec: outlier count and fraction must be within the row count")
+	}
+	if s.Outliers.Count > s.Rows {
+		return errors.New("dataset spec: more outliers than rows")
+	}
+	if s.Outliers.amount(s.Rows) > 0 && s.Outliers.Magnitude == 0 {
+		return errors.New("dataset spec: outliers need a non-zero magnitude")
+	}
+
+	return nil
+}
+
+// same spec always produces the same rows: x design, noise and outliers are drawn in that order from one seeded source
+func Generate(spec Spec) (Dataset, error) {
+	if err := spec.Validate(); err != nil {
+		return Dataset{}, err
+	}
+
+	var rnd = rand.New(rand.NewSource(spec.Seed))
+	var rows = make([]Row, spec.Rows)
+
+	for i := range rows {
+		rows[i].X = spec.X.sample(rnd, i)
+	}
+
+	for i := range rows {
+		rows[i].Y = spec.Model.evaluate(rows[i].X) + spec.Noise.sample(rnd)
+	}
+
+	var outliers = spec.Outliers.amount(spec.Rows)
+	for _, idx := range rnd.Perm(spec.Rows)[:outliers] {
+		var sign = 1.0
+		if rnd.Intn(2) == 0 {
+			sign = -1.0
+		}
+
+		rows[idx].Y += sign * spec.Outliers.Magnitude
+		rows[idx].Outlier = true
+	}
+
+	return Dataset{Spec: spec, Rows: rows}, nil
+}
+
+func (x XDesign) sample(rnd *rand.Rand, i int) float64 {
+	switch x.Kind {
+	case XUniform:
+		return x.Low + rnd.Float64()*(x.High-x.Low)
+	case XNormal:
+		return x.Mean + rnd.NormFloat64()*x.Deviation
+	default:
+		return x.Start + float64(i)*x.Step
+	}
+}
+
+func (m Model) evaluate(x float64) float64 {
+	if m.Kind == ModelPolynomial {
+		// horner scheme
+		var y = 0.0
+		for i := len(m.Coefficients) - 1; i >= 0; i-- {
+			y = y*x + m.Coefficients[i]
+		}
+		return y
+	}
+
+	return m.K*x + m.B
+}
+
+func (n Noise) sample(rnd *rand.Rand) float64 {
+	switch n.Kind {
+	case NoiseNormal:
+		return rnd.NormFloat64() * n.Deviation
+	case NoiseHalfNormal:
+		return math.Abs(rnd.NormFloat64()) * n.Deviation
+	case NoiseUniform:
+		return n.Low + rnd.Float64()*(n.High-n.Low)
+	default:
+		return 0
+	}
+}
+
+func (o Outliers) amount(rows int) int {
+	if o.Count > 0 {
+		return o.Count
+	}
+
+	return int(math.Round(o.Fraction * float64(rows)))
+}
+
+func (d Dataset) GetX() []float64 {
+	var x = make([]float64, len(d.Rows))
+	for i, row := range d.Rows {
+		x[i] = row.X
+	}
+	return x
+}
+
+func (d Dataset) GetY() []float64 {
+	var y = make([]float64, len(d.Rows))
+	for i, row := range d.Rows {
+		y[i] = row.Y
+	}
+	return y
+}
+
+// rows as points, ready for Regression
+func (d Dataset) Points() []point_pkg.Point {
+	var points = make([]point_pkg.Point, len(d.Rows))
+	for i, row := range d.Rows {
+		points[i] = point_pkg.Point{X: float32(row.X), Y: float32(row.Y)}
+	}
+	return points
+}
+
+func (d Dataset) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"x", "y", "outlier"}); err != nil {
+		return err
+	}
+
+	for _, row := range d.Rows {
+		var record = []string{
+			strconv.FormatFloat(row.X, 'g', -1, 64),
+			strconv.FormatFloat(row.Y, 'g', -1, 64),
+			strconv.FormatBool(row.Outlier),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// the spec is embedded so the file can be regenerated from itself
+func (d Dataset) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
@@ -0,0 +1,331 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"shared/interfaces"
+	"sort"
+
+	sample "shared/models/Sample"
+)
+
+// a symmetric kernel in its standard form, it integrates to 1
+type Kernel struct {
+	Name string
+	K    func(u float64) float64
+	// K vanishes outside [−Radius, Radius], +Inf for the gaussian
+	Radius float64
+	// ∫ u² K(u) du, the bandwidth rescales the kernel to this standard deviation
+	Variance float64
+}
+
+var Gaussian = Kernel{
+	Name:     "gaussian",
+	K:        func(u float64) float64 { return math.Exp(-u*u/2) / math.Sqrt(2*math.Pi) },
+	Radius:   math.Inf(1),
+	Variance: 1,
+}
+
+var Epanechnikov = Kernel{
+	Name:     "epanechnikov",
+	K:        bounded(func(u float64) float64 { return 0.75 * (1 - u*u) }),
+	Radius:   1,
+	Variance: 1.0 / 5,
+}
+
+var Triangular = Kernel{
+	Name:     "triangular",
+	K:        bounded(func(u float64) float64 { return 1 - math.Abs(u) }),
+	Radius:   1,
+	Variance: 1.0 / 6,
+}
+
+var Rectangular = Kernel{
+	Name:     "rectangular",
+	K:        bounded(func(u float64) float64 { return 0.5 }),
+	Radius:   1,
+	Variance: 1.0 / 3,
+}
+
+var Biweight = Kernel{
+	Name:     "biweight",
+	K:        bounded(func(u float64) float64 { return 15.0 / 16 * (1 - u*u) * (1 - u*u) }),
+	Radius:   1,
+	Variance: 1.0 / 7,
+}
+
+var Triweight = Kernel{
+	Name:     "triweight",
+	K:        bounded(func(u float64) float64 { return 35.0 / 32 * math.Pow(1-u*u, 3) }),
+	Radius:   1,
+	Variance: 1.0 / 9,
+}
+
+var Cosine = Kernel{
+	Name:     "cosine",
+	K:        bounded(func(u float64) float64 { return math.Pi / 4 * math.Cos(math.Pi/2*u) }),
+	Radius:   1,
+	Variance: 1 - 8/(math.Pi*math.Pi),
+}
+
+var Kernels = []Kernel{Gaussian, Epanechnikov, Triangular, Rectangular, Biweight, Triweight, Cosine}
+
+type Bandwidth int
+
+const (
+	// 0.9 min(sd, IQR/1.34) n^(−1/5), robust to skewness and outliers
+	Silverman Bandwidth = iota
+	// 1.06 sd n^(−1/5), optimal for normal data
+	Scott
+	// solve-the-equation plug-in, follows multimodal and skewed data best
+	SheatherJones
+)
+
+func (b Bandwidth) String() string {
+	switch b {
+	case Scott:
+		return "Scott"
+	case SheatherJones:
+		return "Sheather–Jones"
+	default:
+		return "Silverman"
+	}
+}
+
+// the rules give the standard deviation of a gaussian kernel, it is used for every kernel the same way
+func (b Bandwidth) Select(values []float64) (float64, error) {
+	var n = float64(len(values))
+	if n < 2 {
+		return 0, errors.New("density: at least two values are needed")
+	}
+
+	var sd = sample.StdDev(values)
+	if sd == 0 {
+		return 0, errors.New("density: all values are equal")
+	}
+
+	switch b {
+	case Silverman:
+		return 0.9 * scale(values) * math.Pow(n, -0.2), nil
+	case Scott:
+		return 1.06 * sd * math.Pow(n, -0.2), nil
+	case SheatherJones:
+		return sheatherJones(values)
+	default:
+		return 0, fmt.Errorf("density: unknown bandwidth rule %d", b)
+	}
+}
+
+// kernel density estimate over a sample
+type KDE struct {
+	// ascending
+	Values []float64
+	Kernel Kernel
+	// standard deviation of the scaled kernel, as in R's density()
+	Bandwidth float64
+	Rule      string
+}
+
+// bandwidths beyond the sample that Curve still covers, the tails have faded there
+const Cut = 3
+
+// Curve evaluates directly up to this many values × grid points and bins above it
+const BinnedLimit = 1_000_000
+
+func New(values interfaces.ISample, kernel Kernel, rule Bandwidth) (KDE, error) {
+	var data = values.GetValues()
+	h, err := rule.Select(data)
+	if err != nil {
+		return KDE{}, err
+	}
+
+	var kde, _ = WithBandwidth(values, kernel, h)
+	kde.Rule = rule.String()
+	return kde, nil
+}
+
+func WithBandwidth(values interfaces.ISample, kernel Kernel, bandwidth float64) (KDE, error) {
+	var data = values.GetValues()
+	if len(data) == 0 {
+		return KDE{}, errors.New("density: no values")
+	}
+	if bandwidth <= 0 || math.IsNaN(bandwidth) || math.IsInf(bandwidth, 0) {
+		return KDE{}, fmt.Errorf("density: bandwidth %g must be positive", bandwidth)
+	}
+
+	return KDE{Values: sample.Sorted(data), Kernel: kernel, Bandwidth: bandwidth, Rule: "fixed"}, nil
+}
+
+// the kernel's own scale, its standard form stretched by this has standard deviation Bandwidth
+func (k KDE) width() float64 {
+	return k.Bandwidth / math.Sqrt(k.Kernel.Variance)
+}
+
+func (k KDE) PDF(x float64) float64 {
+	var a = k.width()
+	var from, to = 0, len(k.Values)
+	if !math.IsInf(k.Kernel.Radius, 1) {
+		from = sort.SearchFloat64s(k.Values, x-a*k.Kernel.Radius)
+		to = sort.SearchFloat64s(k.Values, x+a*k.Kernel.Radius)
+		if to < len(k.Values) && k.Values[to] == x+a*k.Kernel.Radius {
+			to++
+		}
+	}
+
+	var sum = 0.0
+	for _, v := range k.Values[from:to] {
+		sum += k.Kernel.K((x - v) / a)
+	}
+	return sum / (float64(len(k.Values)) * a)
+}
+
+// exact density at every grid point
+func (k KDE) Evaluate(grid []float64) []float64 {
+	var densities = make([]float64, len(grid))
+	for i, x := range grid {
+		densities[i] = k.PDF(x)
+	}
+	return densities
+}
+
+// density on points equally spaced grid points over [low, high]. the values are linearly binned onto the
+// grid and convolved with the kernel by FFT, so the cost hardly grows with n. values outside the grid
+// are dropped, as in R's density(), the grid should cover the sample
+func (k KDE) Binned(low, high float64, points int) ([]float64, []float64, error) {
+	if points < 2 || high <= low {
+		return nil, nil, errors.New("density: the grid needs two or more points over a non-empty range")
+	}
+
+	var step = (high - low) / float64(points-1)
+	var grid = make([]float64, points)
+	for i := range grid {
+		grid[i] = low + float64(i)*step
+	}
+
+	// linear binning: each value splits its weight between the two neighbouring grid points
+	var size = 1
+	for size < 2*points {
+		size *= 2
+	}
+	var counts = make([]complex128, size)
+	for _, v := range k.Values {
+		var position = (v - low) / step
+		if position < 0 || position > float64(points-1) {
+			continue
+		}
+		var i = int(position)
+		if i == points-1 {
+			counts[i] += 1
+			continue
+		}
+		var w = position - float64(i)
+		counts[i] += complex(1-w, 0)
+		counts[i+1] += complex(w, 0)
+	}
+
+	// kernel at every grid offset, negative offsets wrap around
+	var a = k.width()
+	var n = float64(len(k.Values))
+	var weights = make([]complex128, size)
+	for j := 0; j < points; j++ {
+		var value = k.Kernel.K(float64(j)*step/a) / (n * a)
+		weights[j] = complex(value, 0)
+		if j > 0 {
+			weights[size-j] = complex(value, 0)
+		}
+	}
+
+	fft(counts, false)
+	fft(weights, false)
+	for i := range counts {
+		counts[i] *= weights[i]
+	}
+	fft(counts, true)
+
+	var densities = make([]float64, points)
+	for i := range densities {
+		densities[i] = math.Max(0, real(counts[i]))
+	}
+
+	return grid, densities, nil
+}
+
+// the density over the sample widened by Cut bandwidths on each side, ready to plot
+func (k KDE) Curve(points int) ([]float64, []float64, error) {
+	var low = k.Values[0] - Cut*k.Bandwidth
+	var high = k.Values[len(k.Values)-1] + Cut*k.Bandwidth
+
+	if len(k.Values)*points > BinnedLimit {
+		return k.Binned(low, high, points)
+	}
+	if points < 2 {
+		return nil, nil, errors.New("density: the grid needs two or more points over a non-empty range")
+	}
+
+	var grid = make([]float64, points)
+	for i := range grid {
+		grid[i] = low + float64(i)*(high-low)/float64(points-1)
+	}
+	return grid, k.Evaluate(grid), nil
+}
+
+// min(sd, IQR/1.349), the sd alone when the quartiles coincide
+func scale(values []float64) float64 {
+	var iqr = sample.Quantile(values, 0.75) - sample.Quantile(values, 0.25)
+	var sd = sample.StdDev(values)
+	if iqr == 0 {
+		return sd
+	}
+	return math.Min(sd, iqr/1.349)
+}
+
+func bounded(k func(u float64) float64) func(u float64) float64 {
+	return func(u float64) float64 {
+		if u < -1 || u > 1 {
+			return 0
+		}
+		return k(u)
+	}
+}
+
+// in-place radix-2 fourier transform, len(a) must be a power of two. inverse divides by len(a)
+func fft(a []complex128, inverse bool) {
+	var n = len(a)
+
+	for i, j := 1, 0; i < n; i++ {
+		var bit = n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+
+	var sign = -1.0
+	if inverse {
+		sign = 1
+	}
+
+	for length := 2; length <= n; length <<= 1 {
+		var w = cmplx.Rect(1, sign*2*math.Pi/float64(length))
+		for start := 0; start < n; start += length {
+			var twiddle = complex(1, 0)
+			for k := 0; k < length/2; k++ {
+				var u, v = a[start+k], a[start+k+length/2] * twiddle
+				a[start+k] = u + v
+				a[start+k+length/2] = u - v
+				twiddle *= w
+			}
+		}
+	}
+
+	if inverse {
+		for i := range a {
+			a[i] /= complex(float64(n), 0)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package shared
+
+import (
+	"errors"
+	"math"
+
+	sample "shared/models/Sample"
+)
+
+// bins the pairwise distances are counted in, as in R's bw.SJ
+const sheatherJonesBins = 1000
+
+// sheather & jones (1991) solve-the-equation bandwidth, after R's bw.SJ(method = "ste"). the density
+// functionals are estimated from pairwise distances binned into sheatherJonesBins classes
+func sheatherJones(values []float64) (float64, error) {
+	var n = float64(len(values))
+	var distance, counts = pairCounts(values)
+
+	// φ⁽⁴⁾ and φ⁽⁶⁾ functionals of the density at bandwidth h
+	var sdh = func(h float64) float64 {
+		var sum = functional(distance, counts, h, func(d float64) float64 { return d*d - 6*d + 3 })
+		return (2*sum + 3*n) / (n * (n - 1) * math.Pow(h, 5) * math.Sqrt(2*math.Pi))
+	}
+	var tdh = func(h float64) float64 {
+		var sum = functional(distance, counts, h, func(d float64) float64 { return d*d*d - 15*d*d + 45*d - 15 })
+		return (2*sum - 15*n) / (n * (n - 1) * math.Pow(h, 7) * math.Sqrt(2*math.Pi))
+	}
+
+	var s = scale(values)
+	var a = 1.24 * s * math.Pow(n, -1.0/7)
+	var b = 1.23 * s * math.Pow(n, -1.0/9)
+	var c1 = 1 / (2 * math.Sqrt(math.Pi) * n)
+
+	var td = -tdh(b)
+	if math.IsNaN(td) || math.IsInf(td, 0) || td <= 0 {
+		return 0, errors.New("density: the sample is too sparse for sheather–jones")
+	}
+	var alpha2 = 1.357 * math.Pow(sdh(a)/td, 1.0/7)
+	if math.IsNaN(alpha2) || math.IsInf(alpha2, 0) {
+		return 0, errors.New("density: the sample is too sparse for sheather–jones")
+	}
+
+	var equation = func(h float64) float64 {
+		return math.Pow(c1/sdh(alpha2*math.Pow(h, 5.0/7)), 0.2) - h
+	}
+
+	// widen the bracket around the normal reference until the equation changes sign
+	var hmax = 1.144 * s * math.Pow(n, -0.2)
+	var lower, upper = 0.1 * hmax, hmax
+	for try := 1; equation(lower)*equation(upper) > 0; try++ {
+		if try > 99 {
+			return 0, errors.New("density: no sheather–jones bandwidth in range")
+		}
+		if try%2 == 1 {
+			upper *= 1.2
+		} else {
+			lower /= 1.2
+		}
+	}
+
+	// bisection
+	var fLower = equation(lower)
+	for upper-lower > 1e-8*upper {
+		var middle = (lower + upper) / 2
+		var fMiddle = equation(middle)
+		if fMiddle*fLower > 0 {
+			lower, fLower = middle, fMiddle
+		} else {
+			upper = middle
+		}
+	}
+
+	return (lower + upper) / 2, nil
+}
+
+// the values binned, and the number of pairs of values i < j whose bins lie k bins apart
+func pairCounts(values []float64) (float64, []float64) {
+	var sorted = sample.Sorted(values)
+	var low, high = sorted[0], sorted[len(sorted)-1]
+	var distance = (high - low) * 1.01 / sheatherJonesBins
+
+	var bins = make([]float64, sheatherJonesBins)
+	for _, v := range sorted {
+		var i = int((v - low) / distance)
+		if i >= sheatherJonesBins {
+			i = sheatherJonesBins - 1
+		}
+		bins[i]++
+	}
+
+	var counts = make([]float64, sheatherJonesBins)
+	for i, x := range bins {
+		if x == 0 {
+			continue
+		}
+		counts[0] += x * (x - 1) / 2
+		for j := i + 1; j < sheatherJonesBins; j++ {
+			counts[j-i] += x * bins[j]
+		}
+	}
+
+	return distance, counts
+}
+
+// Σ counts[k] exp(−δ/2) polynomial(δ) with δ = (k·distance/h)², the far pairs add nothing
+func functional(distance float64, counts []float64, h float64, polynomial func(d float64) float64) float64 {
+	const cutoff = 1000
+
+	var sum = 0.0
+	for k, c := range counts {
+		var d = float64(k) * distance / h
+		d *= d
+		if d >= cutoff {
+			break
+		}
+		sum += c * math.Exp(-d/2) * polynomial(d)
+	}
+	return sum
+}
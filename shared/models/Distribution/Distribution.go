@@ -0,0 +1,624 @@
+package shared
+
+import (
+	"math"
+	"math/rand"
+)
+
+type Normal struct {
+	Mu    float64
+	Sigma float64
+}
+
+func StandardNormal() Normal {
+	return Normal{Mu: 0, Sigma: 1}
+}
+
+func (d Normal) PDF(x float64) float64 {
+	var z = (x - d.Mu) / d.Sigma
+	return math.Exp(-z*z/2) / (d.Sigma * math.Sqrt(2*math.Pi))
+}
+
+func (d Normal) CDF(x float64) float64 {
+	return 0.5 * math.Erfc(-(x-d.Mu)/(d.Sigma*math.Sqrt2))
+}
+
+func (d Normal) Survival(x float64) float64 {
+	return 0.5 * math.Erfc((x-d.Mu)/(d.Sigma*math.Sqrt2))
+}
+
+func (d Normal) Quantile(p float64) float64 {
+	return d.Mu - d.Sigma*math.Sqrt2*math.Erfcinv(2*p)
+}
+
+func (d Normal) Rand(rnd *rand.Rand) float64 {
+	return d.Mu + d.Sigma*rnd.NormFloat64()
+}
+
+func (d Normal) Mean() float64 {
+	return d.Mu
+}
+
+func (d Normal) Variance() float64 {
+	return d.Sigma * d.Sigma
+}
+
+// student t with Nu degrees of freedom
+type StudentT struct {
+	Nu float64
+}
+
+func (d StudentT) PDF(x float64) float64 {
+	var logDensity = -LogBeta(0.5, d.Nu/2) - 0.5*math.Log(d.Nu) - (d.Nu+1)/2*math.Log1p(x*x/d.Nu)
+	return math.Exp(logDensity)
+}
+
+func (d StudentT) CDF(x float64) float64 {
+	var tail = 0.5 * BetaI(d.Nu/2, 0.5, d.Nu/(d.Nu+x*x))
+	if x > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+func (d StudentT) Survival(x float64) float64 {
+	return d.CDF(-x)
+}
+
+func (d StudentT) Quantile(p float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	if p == 0.5 {
+		return 0
+	}
+	if p < 0.5 {
+		return -d.Quantile(1 - p)
+	}
+
+	var guess = StandardNormal().Quantile(p)
+	return invert(d.CDF, p, 0, 2*guess+1, math.Inf(-1))
+}
+
+func (d StudentT) Rand(rnd *rand.Rand) float64 {
+	var chi = ChiSquared{K: d.Nu}.Rand(rnd)
+	return rnd.NormFloat64() / math.Sqrt(chi/d.Nu)
+}
+
+// noncentral t with Nu degrees of freedom and noncentrality Delta, the distribution of a t statistic under H1
+type NoncentralT struct {
+	Nu    float64
+	Delta float64
+}
+
+// algorithm AS 243 (Lenth, 1989)
+func (d NoncentralT) CDF(t float64) float64 {
+	const errmax = 1e-12
+	const itrmax = 1000
+
+	var negdel = false
+	var tt, del = t, d.Delta
+	if t < 0 {
+		negdel = true
+		tt, del = -t, -d.Delta
+	}
+
+	var x = tt * tt / (tt*tt + d.Nu)
+	var tnc = 0.0
+
+	if x > 0 {
+		var lambda = del * del
+		var p = 0.5 * math.Exp(-0.5*lambda)
+		var q = math.Sqrt(2/math.Pi) * p * del
+		var s = 0.5 - p
+		var a = 0.5
+		var b = 0.5 * d.Nu
+		var rxb = math.Pow(1-x, b)
+		var albeta = LogBeta(a, b)
+		var xodd = BetaI(a, b, x)
+		var godd = 2 * rxb * math.Exp(a*math.Log(x)-albeta)
+		var xeven = 1 - rxb
+		var geven = b * x * rxb
+		tnc = p*xodd + q*xeven
+
+		for en := 1.0; en <= itrmax; en++ {
+			a++
+			xodd -= godd
+			xeven -= geven
+			godd *= x * (a + b - 1) / a
+			geven *= x * (a + b - 0.5) / (a + 0.5)
+			p *= lambda / (2 * en)
+			q *= lambda / (2*en + 1)
+			s -= p
+			tnc += p*xodd + q*xeven
+
+			if 2*s*(xodd-godd) <= errmax {
+				break
+			}
+		}
+	}
+
+	tnc += StandardNormal().CDF(-del)
+
+	if negdel {
+		tnc = 1 - tnc
+	}
+
+	return math.Min(1, math.Max(0, tnc))
+}
+
+func (d NoncentralT) Quantile(p float64) float64 {
+	var guess = d.Delta + StandardNormal().Quantile(p)
+	return invert(d.CDF, p, guess-1, guess+1, math.Inf(-1))
+}
+
+// chi-squared with K degrees of freedom
+type ChiSquared struct {
+	K float64
+}
+
+func (d ChiSquared) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	var k2 = d.K / 2
+	lg, _ := math.Lgamma(k2)
+	return math.Exp((k2-1)*math.Log(x) - x/2 - k2*math.Ln2 - lg)
+}
+
+func (d ChiSquared) CDF(x float64) float64 {
+	return GammaP(d.K/2, x/2)
+}
+
+func (d ChiSquared) Survival(x float64) float64 {
+	return GammaQ(d.K/2, x/2)
+}
+
+func (d ChiSquared) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	return invert(d.CDF, p, 0, d.K+1, 0)
+}
+
+func (d ChiSquared) Rand(rnd *rand.Rand) float64 {
+	return Gamma{Shape: d.K / 2, Scale: 2}.Rand(rnd)
+}
+
+func (d ChiSquared) Mean() float64 {
+	return d.K
+}
+
+func (d ChiSquared) Variance() float64 {
+	return 2 * d.K
+}
+
+// noncentral chi-squared with K degrees of freedom and noncentrality Lambda
+type NoncentralChiSquared struct {
+	K      float64
+	Lambda float64
+}
+
+// poisson(Lambda/2) mixture of central chi-squared cdfs, summed outwards from the largest weight
+func (d NoncentralChiSquared) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if d.Lambda == 0 {
+		return ChiSquared{K: d.K}.CDF(x)
+	}
+
+	var half = d.Lambda / 2
+	var mode = math.Floor(half)
+	var term = func(j float64) float64 {
+		lj, _ := math.Lgamma(j + 1)
+		var weight = math.Exp(-half + j*math.Log(half) - lj)
+		return weight * GammaP(d.K/2+j, x/2)
+	}
+
+	var sum = term(mode)
+	for j := mode + 1; ; j++ {
+		var t = term(j)
+		sum += t
+		if t < 1e-15*sum || j-mode > 10000 {
+			break
+		}
+	}
+	for j := mode - 1; j >= 0; j-- {
+		var t = term(j)
+		sum += t
+		if t < 1e-15*sum {
+			break
+		}
+	}
+
+	return math.Min(1, sum)
+}
+
+func (d NoncentralChiSquared) Survival(x float64) float64 {
+	return 1 - d.CDF(x)
+}
+
+func (d NoncentralChiSquared) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	return invert(d.CDF, p, 0, d.K+d.Lambda+1, 0)
+}
+
+func (d NoncentralChiSquared) Mean() float64 {
+	return d.K + d.Lambda
+}
+
+func (d NoncentralChiSquared) Variance() float64 {
+	return 2 * (d.K + 2*d.Lambda)
+}
+
+type Gamma struct {
+	Shape float64
+	Scale float64
+}
+
+func (d Gamma) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(d.Shape)
+	return math.Exp((d.Shape-1)*math.Log(x) - x/d.Scale - d.Shape*math.Log(d.Scale) - lg)
+}
+
+func (d Gamma) CDF(x float64) float64 {
+	return GammaP(d.Shape, x/d.Scale)
+}
+
+func (d Gamma) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	return invert(d.CDF, p, 0, d.Shape*d.Scale+d.Scale, 0)
+}
+
+// marsaglia-tsang squeeze method, shapes below one are boosted by U^(1/shape)
+func (d Gamma) Rand(rnd *rand.Rand) float64 {
+	var shape = d.Shape
+	var boost = 1.0
+	if shape < 1 {
+		boost = math.Pow(rnd.Float64(), 1/shape)
+		shape++
+	}
+
+	var dd = shape - 1.0/3
+	var c = 1 / math.Sqrt(9*dd)
+
+	for {
+		var x = rnd.NormFloat64()
+		var v = 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		var u = rnd.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+dd*(1-v+math.Log(v)) {
+			return dd * v * d.Scale * boost
+		}
+	}
+}
+
+func (d Gamma) Mean() float64 {
+	return d.Shape * d.Scale
+}
+
+func (d Gamma) Variance() float64 {
+	return d.Shape * d.Scale * d.Scale
+}
+
+type Exponential struct {
+	Rate float64
+}
+
+func (d Exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.Rate * math.Exp(-d.Rate*x)
+}
+
+func (d Exponential) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-d.Rate * x)
+}
+
+func (d Exponential) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return math.Exp(-d.Rate * x)
+}
+
+func (d Exponential) Quantile(p float64) float64 {
+	return -math.Log1p(-p) / d.Rate
+}
+
+func (d Exponential) Rand(rnd *rand.Rand) float64 {
+	return rnd.ExpFloat64() / d.Rate
+}
+
+func (d Exponential) Mean() float64 {
+	return 1 / d.Rate
+}
+
+func (d Exponential) Variance() float64 {
+	return 1 / (d.Rate * d.Rate)
+}
+
+// fisher–snedecor F with D1 and D2 degrees of freedom
+type FisherF struct {
+	D1 float64
+	D2 float64
+}
+
+func (d FisherF) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	var logDensity = 0.5*(d.D1*math.Log(d.D1*x)+d.D2*math.Log(d.D2)-(d.D1+d.D2)*math.Log(d.D1*x+d.D2)) - math.Log(x) - LogBeta(d.D1/2, d.D2/2)
+	return math.Exp(logDensity)
+}
+
+func (d FisherF) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return BetaI(d.D1/2, d.D2/2, d.D1*x/(d.D1*x+d.D2))
+}
+
+func (d FisherF) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return BetaI(d.D2/2, d.D1/2, d.D2/(d.D1*x+d.D2))
+}
+
+func (d FisherF) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	return invert(d.CDF, p, 0, 2, 0)
+}
+
+type Binomial struct {
+	N int
+	P float64
+}
+
+func (d Binomial) PMF(k int) float64 {
+	if k < 0 || k > d.N {
+		return 0
+	}
+	if d.P == 0 || d.P == 1 {
+		if (d.P == 0 && k == 0) || (d.P == 1 && k == d.N) {
+			return 1
+		}
+		return 0
+	}
+	ln, _ := math.Lgamma(float64(d.N + 1))
+	lk, _ := math.Lgamma(float64(k + 1))
+	lnk, _ := math.Lgamma(float64(d.N - k + 1))
+	return math.Exp(ln - lk - lnk + float64(k)*math.Log(d.P) + float64(d.N-k)*math.Log1p(-d.P))
+}
+
+// P(X ≤ k)
+func (d Binomial) CDF(k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	if k >= d.N {
+		return 1
+	}
+	return BetaI(float64(d.N-k), float64(k+1), 1-d.P)
+}
+
+// P(X ≥ k)
+func (d Binomial) Survival(k int) float64 {
+	return 1 - d.CDF(k-1)
+}
+
+func (d Binomial) Rand(rnd *rand.Rand) int {
+	var k = 0
+	for i := 0; i < d.N; i++ {
+		if rnd.Float64() < d.P {
+			k++
+		}
+	}
+	return k
+}
+
+func (d Binomial) Mean() float64 {
+	return float64(d.N) * d.P
+}
+
+func (d Binomial) Variance() float64 {
+	return float64(d.N) * d.P * (1 - d.P)
+}
+
+// continuous uniform on [Low, High]
+type Uniform struct {
+	Low  float64
+	High float64
+}
+
+func (d Uniform) PDF(x float64) float64 {
+	if x < d.Low || x > d.High {
+		return 0
+	}
+	return 1 / (d.High - d.Low)
+}
+
+func (d Uniform) CDF(x float64) float64 {
+	return math.Min(1, math.Max(0, (x-d.Low)/(d.High-d.Low)))
+}
+
+func (d Uniform) Quantile(p float64) float64 {
+	return d.Low + p*(d.High-d.Low)
+}
+
+func (d Uniform) Rand(rnd *rand.Rand) float64 {
+	return d.Low + rnd.Float64()*(d.High-d.Low)
+}
+
+func (d Uniform) Mean() float64 {
+	return (d.Low + d.High) / 2
+}
+
+func (d Uniform) Variance() float64 {
+	return (d.High - d.Low) * (d.High - d.Low) / 12
+}
+
+// exp of a Normal{Mu, Sigma}
+type LogNormal struct {
+	Mu    float64
+	Sigma float64
+}
+
+func (d LogNormal) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return Normal{Mu: d.Mu, Sigma: d.Sigma}.PDF(math.Log(x)) / x
+}
+
+func (d LogNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return Normal{Mu: d.Mu, Sigma: d.Sigma}.CDF(math.Log(x))
+}
+
+func (d LogNormal) Quantile(p float64) float64 {
+	return math.Exp(Normal{Mu: d.Mu, Sigma: d.Sigma}.Quantile(p))
+}
+
+func (d LogNormal) Rand(rnd *rand.Rand) float64 {
+	return math.Exp(d.Mu + d.Sigma*rnd.NormFloat64())
+}
+
+func (d LogNormal) Mean() float64 {
+	return math.Exp(d.Mu + d.Sigma*d.Sigma/2)
+}
+
+func (d LogNormal) Variance() float64 {
+	var s2 = d.Sigma * d.Sigma
+	return math.Expm1(s2) * math.Exp(2*d.Mu+s2)
+}
+
+type Weibull struct {
+	Shape float64
+	Scale float64
+}
+
+func (d Weibull) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	var z = x / d.Scale
+	return d.Shape / d.Scale * math.Pow(z, d.Shape-1) * math.Exp(-math.Pow(z, d.Shape))
+}
+
+func (d Weibull) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-math.Pow(x/d.Scale, d.Shape))
+}
+
+func (d Weibull) Quantile(p float64) float64 {
+	return d.Scale * math.Pow(-math.Log1p(-p), 1/d.Shape)
+}
+
+func (d Weibull) Rand(rnd *rand.Rand) float64 {
+	return d.Scale * math.Pow(rnd.ExpFloat64(), 1/d.Shape)
+}
+
+func (d Weibull) Mean() float64 {
+	return d.Scale * math.Gamma(1+1/d.Shape)
+}
+
+func (d Weibull) Variance() float64 {
+	var g1 = math.Gamma(1 + 1/d.Shape)
+	return d.Scale * d.Scale * (math.Gamma(1+2/d.Shape) - g1*g1)
+}
+
+type Poisson struct {
+	Lambda float64
+}
+
+func (d Poisson) PMF(k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	if d.Lambda == 0 {
+		if k == 0 {
+			return 1
+		}
+		return 0
+	}
+	lk, _ := math.Lgamma(float64(k + 1))
+	return math.Exp(float64(k)*math.Log(d.Lambda) - d.Lambda - lk)
+}
+
+// P(X ≤ k)
+func (d Poisson) CDF(k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	return GammaQ(float64(k+1), d.Lambda)
+}
+
+// P(X ≥ k)
+func (d Poisson) Survival(k int) float64 {
+	return 1 - d.CDF(k-1)
+}
+
+// inversion from zero, large means are split in halves so exp(−λ) stays representable
+func (d Poisson) Rand(rnd *rand.Rand) int {
+	if d.Lambda > 500 {
+		var half = Poisson{Lambda: d.Lambda / 2}
+		return half.Rand(rnd) + half.Rand(rnd)
+	}
+
+	var u = rnd.Float64()
+	var p = math.Exp(-d.Lambda)
+	var cdf = p
+	var k = 0
+	for u > cdf && p > 0 {
+		k++
+		p *= d.Lambda / float64(k)
+		cdf += p
+	}
+	return k
+}
+
+func (d Poisson) Mean() float64 {
+	return d.Lambda
+}
+
+func (d Poisson) Variance() float64 {
+	return d.Lambda
+}
@@ -0,0 +1,210 @@
+package shared
+
+import "math"
+
+const (
+	epsilon    = 1e-15
+	iterations = 1000
+	tiny       = 1e-300
+)
+
+// regularized lower incomplete gamma P(a, x)
+func GammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if math.IsInf(x, 1) {
+		return 1
+	}
+	if x < a+1 {
+		return gammaSeries(a, x)
+	}
+	return 1 - gammaContinuedFraction(a, x)
+}
+
+// regularized upper incomplete gamma Q(a, x) = 1 - P(a, x)
+func GammaQ(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	if math.IsInf(x, 1) {
+		return 0
+	}
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaContinuedFraction(a, x)
+}
+
+func gammaSeries(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	var ap = a
+	var sum = 1 / a
+	var del = sum
+
+	for n := 0; n < iterations; n++ {
+		ap++
+		del *= x / ap
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*epsilon {
+			break
+		}
+	}
+
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+// modified lentz evaluation of the continued fraction for Q(a, x)
+func gammaContinuedFraction(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	var b = x + 1 - a
+	var c = 1 / tiny
+	var d = 1 / b
+	var h = d
+
+	for i := 1; i <= iterations; i++ {
+		var an = -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		var del = d * c
+		h *= del
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
+
+// log of the beta function
+func LogBeta(a, b float64) float64 {
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	return la + lb - lab
+}
+
+// k!, +Inf beyond 170!
+func Factorial(k int) float64 {
+	var f = 1.0
+	for i := 2; i <= k; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// n! / (s_1! s_2! ...) with n = s_1 + s_2 + ..., the ways to split n items into groups of the given sizes
+func Multinomial(sizes []int) float64 {
+	var n = 0
+	var logCount = 0.0
+	for _, s := range sizes {
+		n += s
+		ls, _ := math.Lgamma(float64(s + 1))
+		logCount -= ls
+	}
+	ln, _ := math.Lgamma(float64(n + 1))
+
+	return math.Exp(ln + logCount)
+}
+
+// regularized incomplete beta I_x(a, b)
+func BetaI(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	var front = math.Exp(a*math.Log(x) + b*math.Log1p(-x) - LogBeta(a, b))
+
+	// the continued fraction converges fast only below the mean, use symmetry otherwise
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+func betaContinuedFraction(a, b, x float64) float64 {
+	var qab = a + b
+	var qap = a + 1
+	var qam = a - 1
+	var c = 1.0
+	var d = 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	var h = d
+
+	for m := 1; m <= iterations; m++ {
+		var fm = float64(m)
+		var m2 = 2 * fm
+
+		var aa = fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		var del = d * c
+		h *= del
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+
+	return h
+}
+
+// inverts a monotone cdf by bracketing and bisection. lo/hi is the starting bracket, it is widened while it does not contain p
+func invert(cdf func(float64) float64, p, lo, hi, floor float64) float64 {
+	for cdf(lo) > p && lo > floor {
+		var width = hi - lo
+		hi = lo
+		lo = math.Max(floor, lo-2*width)
+	}
+	for cdf(hi) < p {
+		var width = hi - lo
+		lo = hi
+		hi += 2 * width
+	}
+
+	for i := 0; i < 200; i++ {
+		var mid = lo + (hi-lo)/2
+		if cdf(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+		if hi-lo <= 1e-13*math.Max(1, math.Abs(mid)) {
+			break
+		}
+	}
+
+	return lo + (hi-lo)/2
+}
@@ -0,0 +1,191 @@
+package shared
+
+import "math"
+
+// range of K standard normal means divided by an independent standard deviation estimate with Nu degrees of freedom.
+// tukey's HSD statistic follows it
+type StudentizedRange struct {
+	K  float64
+	Nu float64
+}
+
+var (
+	rangeNodes = [6]float64{
+		0.981560634246719250690549090149,
+		0.904117256370474856678465866119,
+		0.769902674194304687036893833213,
+		0.587317954286617447296702418941,
+		0.367831498998180193752691536644,
+		0.125233408511468915472441369464,
+	}
+	rangeWeights = [6]float64{
+		0.047175336386511827194615961485,
+		0.106939325995318430960254718194,
+		0.160078328543346226334652529543,
+		0.203167426723065921749064455810,
+		0.233492536538354808760849898925,
+		0.249147045813402785000562436043,
+	}
+	studentizedNodes = [8]float64{
+		0.989400934991649932596154173450,
+		0.944575023073232576077988415535,
+		0.865631202387831743880467897712,
+		0.755404408355003033895101194847,
+		0.617876244402643748446671764049,
+		0.458016777657227386342419442984,
+		0.281603550779258913230460501460,
+		0.950125098376374401853193354250e-1,
+	}
+	studentizedWeights = [8]float64{
+		0.271524594117540948517805724560e-1,
+		0.622535239386478928628438369944e-1,
+		0.951585116824927848099251076022e-1,
+		0.124628971255533872052476282192,
+		0.149595988816576732081501730547,
+		0.169156519395002538189312079030,
+		0.182603415044923588866763667969,
+		0.189450610455068496285396723208,
+	}
+)
+
+// copenhaver & holland (1988): gauss–legendre integration of the range cdf over the chi distribution of the scale
+func (d StudentizedRange) CDF(q float64) float64 {
+	if q <= 0 {
+		return 0
+	}
+	if math.IsInf(q, 1) {
+		return 1
+	}
+	if d.Nu > 25000 {
+		return normalRangeCDF(q, d.K)
+	}
+
+	var f2 = d.Nu * 0.5
+	lg, _ := math.Lgamma(f2)
+	var f2lf = f2*math.Log(d.Nu) - d.Nu*math.Ln2 - lg
+	var f21 = f2 - 1
+	var ff4 = d.Nu * 0.25
+
+	var ulen = 0.125
+	switch {
+	case d.Nu <= 100:
+		ulen = 1
+	case d.Nu <= 800:
+		ulen = 0.5
+	case d.Nu <= 5000:
+		ulen = 0.25
+	}
+	f2lf += math.Log(ulen)
+
+	var ans = 0.0
+	for i := 1; i <= 50; i++ {
+		var otsum = 0.0
+		var twa1 = float64(2*i-1) * ulen
+
+		for jj := 1; jj <= 16; jj++ {
+			var j int
+			var offset float64
+			if jj > 8 {
+				j = jj - 9
+				offset = studentizedNodes[j] * ulen
+			} else {
+				j = jj - 1
+				offset = -studentizedNodes[j] * ulen
+			}
+
+			var t1 = f2lf + f21*math.Log(twa1+offset) - (offset+twa1)*ff4
+			if t1 >= -30 {
+				var qsqz = q * math.Sqrt((offset+twa1)*0.5)
+				otsum += normalRangeCDF(qsqz, d.K) * studentizedWeights[j] * math.Exp(t1)
+			}
+		}
+
+		if float64(i)*ulen >= 1 && otsum <= 1e-14 {
+			break
+		}
+		ans += otsum
+	}
+
+	return math.Min(1, ans)
+}
+
+func (d StudentizedRange) Survival(q float64) float64 {
+	return 1 - d.CDF(q)
+}
+
+func (d StudentizedRange) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	return invert(d.CDF, p, 0, 4, 0)
+}
+
+// cdf of the range of cc standard normals (known variance)
+func normalRangeCDF(w, cc float64) float64 {
+	var qsqz = w * 0.5
+	if qsqz >= 8 {
+		return 1
+	}
+
+	var normal = StandardNormal()
+	var prW = 2*normal.CDF(qsqz) - 1
+	if prW >= math.Exp(-50/cc) {
+		prW = math.Pow(prW, cc)
+	} else {
+		prW = 0
+	}
+
+	var wincr = 3.0
+	if w > 3 {
+		wincr = 2
+	}
+
+	var blb = qsqz
+	var binc = (8 - qsqz) / wincr
+	var bub = blb + binc
+	var einsum = 0.0
+	var cc1 = cc - 1
+
+	for wi := 1.0; wi <= wincr; wi++ {
+		var elsum = 0.0
+		var a = 0.5 * (bub + blb)
+		var b = 0.5 * (bub - blb)
+
+		for jj := 1; jj <= 12; jj++ {
+			var j int
+			var xx float64
+			if jj > 6 {
+				j = 12 - jj
+				xx = rangeNodes[j]
+			} else {
+				j = jj - 1
+				xx = -rangeNodes[j]
+			}
+
+			var ac = a + b*xx
+			var qexpo = ac * ac
+			if qexpo > 60 {
+				break
+			}
+
+			var rinsum = normal.CDF(ac) - normal.CDF(ac-w)
+			if rinsum >= math.Exp(-30/cc1) {
+				elsum += rangeWeights[j] * math.Exp(-0.5*qexpo) * math.Pow(rinsum, cc1)
+			}
+		}
+
+		einsum += elsum * 2 * b * cc / math.Sqrt(2*math.Pi)
+		blb = bub
+		bub += binc
+	}
+
+	prW += einsum
+	if prW <= math.Exp(-30) {
+		return 0
+	}
+
+	return math.Min(1, prW)
+}
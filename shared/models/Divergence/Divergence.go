@@ -0,0 +1,367 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	ecdf "shared/models/ECDF"
+)
+
+// probabilities over ascending support points. tables, bins and densities on a grid all become one,
+// two of them are compared on the union of their supports, missing points having probability zero
+type Discrete struct {
+	Support       []float64
+	Probabilities []float64
+}
+
+// one measure between p and q. Infinite says p puts mass where q has none, the value is +Inf then
+type Distance struct {
+	Name     string
+	Value    float64
+	Infinite bool
+}
+
+// frequencies of every variant, normalised
+func FromTable(d interfaces.IDistribution) (Discrete, error) {
+	var variants, occurences = d.GetVariants(), d.GetOccurences()
+	var support, counts = []float64{}, []float64{}
+	for i, x := range variants {
+		if occurences[i] > 0 {
+			support = append(support, float64(x))
+			counts = append(counts, float64(occurences[i]))
+		}
+	}
+	return FromCounts(support, counts)
+}
+
+// counts or weights at support points, normalised. points may repeat, their weights add up
+func FromCounts(support, counts []float64) (Discrete, error) {
+	if len(support) != len(counts) {
+		return Discrete{}, errors.New("divergence: support and counts differ in length")
+	}
+
+	var total = 0.0
+	var weights = map[float64]float64{}
+	for i, c := range counts {
+		if c < 0 || math.IsNaN(c) || math.IsInf(c, 0) {
+			return Discrete{}, fmt.Errorf("divergence: invalid weight %g", c)
+		}
+		weights[support[i]] += c
+		total += c
+	}
+	if total == 0 {
+		return Discrete{}, errors.New("divergence: all weights are zero")
+	}
+
+	var d = Discrete{}
+	for x := range weights {
+		d.Support = append(d.Support, x)
+	}
+	sort.Float64s(d.Support)
+	for _, x := range d.Support {
+		d.Probabilities = append(d.Probabilities, weights[x]/total)
+	}
+
+	return d, nil
+}
+
+// a sample binned like GoodnessOfFit.ContinuousFit: edges are the inner boundaries, the outer bins reach
+// ±infinity and bins are [edge, next edge). every bin keeps its place, empty ones with probability zero
+func FromSample(values interfaces.ISample, edges []float64) (Discrete, error) {
+	var data = values.GetValues()
+	var counts = make([]float64, len(edges)+1)
+	for _, v := range data {
+		var i = sort.SearchFloat64s(edges, v)
+		if i < len(edges) && edges[i] == v {
+			i++
+		}
+		counts[i]++
+	}
+
+	return binned(edges, counts)
+}
+
+// the probability a distribution gives every bin of FromSample, the tails included
+func FromCDF(cdf func(x float64) float64, edges []float64) (Discrete, error) {
+	var bounds = append(append([]float64{math.Inf(-1)}, edges...), math.Inf(1))
+	var masses = make([]float64, len(bounds)-1)
+	for i := range masses {
+		masses[i] = math.Max(0, cdf(bounds[i+1])-cdf(bounds[i]))
+	}
+
+	return binned(edges, masses)
+}
+
+// probabilities of low..high, the tails below low and above high added to the end points
+func FromPMF(pmf func(k int) float64, cdf func(k int) float64, low, high int) (Discrete, error) {
+	var support, masses = []float64{}, []float64{}
+	for k := low; k <= high; k++ {
+		var mass = pmf(k)
+		if k == low {
+			mass = cdf(low)
+		}
+		if k == high {
+			mass = 1 - cdf(high-1)
+			if low == high {
+				mass = 1
+			}
+		}
+		support = append(support, float64(k))
+		masses = append(masses, mass)
+	}
+
+	return FromCounts(support, masses)
+}
+
+// a density at points equally spaced x over [low, high], each carrying density × spacing.
+// continuous distributions or a KDE compare this way
+func FromDensity(pdf func(x float64) float64, low, high float64, points int) (Discrete, error) {
+	if points < 2 || high <= low {
+		return Discrete{}, errors.New("divergence: the grid needs two or more points over a non-empty range")
+	}
+
+	var support, masses = make([]float64, points), make([]float64, points)
+	for i := range support {
+		support[i] = low + float64(i)*(high-low)/float64(points-1)
+		masses[i] = pdf(support[i])
+	}
+
+	return FromCounts(support, masses)
+}
+
+// empty bins count in the comparison, so the support keeps every bin. the outer bins sit half a bin
+// beyond the outer edges, for GoodnessOfFit.IntegerEdges the points are the integers themselves
+func binned(edges, masses []float64) (Discrete, error) {
+	var support = make([]float64, len(masses))
+	switch len(edges) {
+	case 0:
+		support[0] = 0
+	case 1:
+		support[0], support[1] = edges[0]-0.5, edges[0]+0.5
+	default:
+		for i := 1; i < len(edges); i++ {
+			support[i] = (edges[i-1] + edges[i]) / 2
+		}
+		support[0] = edges[0] - (edges[1]-edges[0])/2
+		support[len(edges)] = edges[len(edges)-1] + (edges[len(edges)-1]-edges[len(edges)-2])/2
+	}
+
+	var total = 0.0
+	for _, m := range masses {
+		total += m
+	}
+	if total == 0 {
+		return Discrete{}, errors.New("divergence: all weights are zero")
+	}
+
+	var d = Discrete{Support: support, Probabilities: make([]float64, len(masses))}
+	for i, m := range masses {
+		d.Probabilities[i] = m / total
+	}
+	return d, nil
+}
+
+// p and q over the union of their supports
+func align(p, q Discrete) ([]float64, []float64, []float64) {
+	var support = []float64{}
+	var pp, qq = []float64{}, []float64{}
+	var i, j = 0, 0
+	for i < len(p.Support) || j < len(q.Support) {
+		switch {
+		case j == len(q.Support) || (i < len(p.Support) && p.Support[i] < q.Support[j]):
+			support, pp, qq = append(support, p.Support[i]), append(pp, p.Probabilities[i]), append(qq, 0)
+			i++
+		case i == len(p.Support) || q.Support[j] < p.Support[i]:
+			support, pp, qq = append(support, q.Support[j]), append(pp, 0), append(qq, q.Probabilities[j])
+			j++
+		default:
+			support, pp, qq = append(support, p.Support[i]), append(pp, p.Probabilities[i]), append(qq, q.Probabilities[j])
+			i++
+			j++
+		}
+	}
+	return support, pp, qq
+}
+
+// additive smoothing: pseudo is added to every probability on the union of the supports and both are
+// renormalised, so KullbackLeibler stays finite. it biases every measure towards zero
+func Smooth(p, q Discrete, pseudo float64) (Discrete, Discrete) {
+	var support, pp, qq = align(p, q)
+	var smooth = func(probabilities []float64) Discrete {
+		var total = 1 + pseudo*float64(len(support))
+		var d = Discrete{Support: support, Probabilities: make([]float64, len(support))}
+		for i, v := range probabilities {
+			d.Probabilities[i] = (v + pseudo) / total
+		}
+		return d
+	}
+	return smooth(pp), smooth(qq)
+}
+
+// support points where p has mass and q has none, they make KL(p‖q) infinite
+func Uncovered(p, q Discrete) []float64 {
+	var support, pp, qq = align(p, q)
+	var points = []float64{}
+	for i := range support {
+		if pp[i] > 0 && qq[i] == 0 {
+			points = append(points, support[i])
+		}
+	}
+	return points
+}
+
+// KL(p‖q) = Σ p ln(p/q) in nats. terms with p = 0 vanish, p > 0 where q = 0 makes it +Inf
+func KullbackLeibler(p, q Discrete) float64 {
+	var _, pp, qq = align(p, q)
+	var sum = 0.0
+	for i := range pp {
+		if pp[i] == 0 {
+			continue
+		}
+		if qq[i] == 0 {
+			return math.Inf(1)
+		}
+		sum += pp[i] * math.Log(pp[i]/qq[i])
+	}
+	return math.Max(0, sum)
+}
+
+// ½ KL(p‖m) + ½ KL(q‖m) with m the average of p and q, symmetric and finite: at most ln 2
+func JensenShannon(p, q Discrete) float64 {
+	var support, pp, qq = align(p, q)
+	var m = Discrete{Support: support, Probabilities: make([]float64, len(support))}
+	for i := range support {
+		m.Probabilities[i] = (pp[i] + qq[i]) / 2
+	}
+	return (KullbackLeibler(p, m) + KullbackLeibler(q, m)) / 2
+}
+
+// Σ √(p q), 1 for equal distributions and 0 for disjoint ones
+func BhattacharyyaCoefficient(p, q Discrete) float64 {
+	var _, pp, qq = align(p, q)
+	var sum = 0.0
+	for i := range pp {
+		sum += math.Sqrt(pp[i] * qq[i])
+	}
+	return math.Min(1, sum)
+}
+
+// −ln of the coefficient, +Inf for disjoint supports
+func Bhattacharyya(p, q Discrete) float64 {
+	return math.Max(0, -math.Log(BhattacharyyaCoefficient(p, q)))
+}
+
+// √(1 − Σ √(p q)), between 0 and 1
+func Hellinger(p, q Discrete) float64 {
+	return math.Sqrt(math.Max(0, 1-BhattacharyyaCoefficient(p, q)))
+}
+
+// ½ Σ |p − q|, the largest difference in probability the two give any event
+func TotalVariation(p, q Discrete) float64 {
+	var _, pp, qq = align(p, q)
+	var sum = 0.0
+	for i := range pp {
+		sum += math.Abs(pp[i] - qq[i])
+	}
+	return sum / 2
+}
+
+// (∫₀¹ |Qp(u) − Qq(u)|^order du)^(1/order) over the quantile functions of p and q. order 1 is the earth mover's
+// distance, the work to move p's mass onto q's. zero-probability points cost nothing
+func Wasserstein(p, q Discrete, order float64) (float64, error) {
+	if order < 1 {
+		return 0, fmt.Errorf("divergence: wasserstein order %g is below 1", order)
+	}
+
+	var sum, u = 0.0, 0.0
+	var cp, cq = 0.0, 0.0
+	var i, j = 0, 0
+	for i < len(p.Support) && j < len(q.Support) {
+		var nextP, nextQ = cp + p.Probabilities[i], cq + q.Probabilities[j]
+		var next = math.Min(nextP, nextQ)
+
+		if next > u {
+			sum += (next - u) * math.Pow(math.Abs(p.Support[i]-q.Support[j]), order)
+			u = next
+		}
+
+		// a point is used up when its cumulative probability is reached, up to rounding
+		if nextP <= next+1e-12 {
+			cp = nextP
+			i++
+		}
+		if nextQ <= next+1e-12 {
+			cq = nextQ
+			j++
+		}
+	}
+
+	return math.Pow(sum, 1/order), nil
+}
+
+// the exact wasserstein distance of two raw samples, through their empirical distribution functions
+func WassersteinSamples(a, b interfaces.ISample, order float64) (float64, error) {
+	ea, err := ecdf.New(a)
+	if err != nil {
+		return 0, err
+	}
+	eb, err := ecdf.New(b)
+	if err != nil {
+		return 0, err
+	}
+	return ecdf.Wasserstein(ea, eb, order)
+}
+
+// every measure between p and q
+func All(p, q Discrete) []Distance {
+	var infinite = len(Uncovered(p, q)) > 0
+	var w1, _ = Wasserstein(p, q, 1)
+	var w2, _ = Wasserstein(p, q, 2)
+	var bhattacharyya = Bhattacharyya(p, q)
+
+	return []Distance{
+		{Name: "Kullback–Leibler", Value: KullbackLeibler(p, q), Infinite: infinite},
+		{Name: "Jensen–Shannon", Value: JensenShannon(p, q)},
+		{Name: "Hellinger", Value: Hellinger(p, q)},
+		{Name: "Total variation", Value: TotalVariation(p, q)},
+		{Name: "Bhattacharyya", Value: bhattacharyya, Infinite: math.IsInf(bhattacharyya, 1)},
+		{Name: "Wasserstein-1", Value: w1},
+		{Name: "Wasserstein-2", Value: w2},
+	}
+}
+
+// one row per compared pair, e.g. the sample against several fits
+func WriteTable(w io.Writer, names []string, rows [][]Distance) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	fmt.Fprint(writer, " \t")
+	for _, d := range rows[0] {
+		fmt.Fprintf(writer, " %s\t", d.Name)
+	}
+	fmt.Fprintln(writer)
+
+	for i, row := range rows {
+		fmt.Fprintf(writer, " %s\t", names[i])
+		for _, d := range row {
+			if d.Infinite {
+				fmt.Fprint(writer, " ∞\t")
+				continue
+			}
+			fmt.Fprintf(writer, " %.4f\t", d.Value)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,177 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	sample "shared/models/Sample"
+)
+
+// empirical distribution function of a sample, F(x) = #{values ≤ x} / n
+type ECDF struct {
+	// ascending
+	Values []float64
+}
+
+// one jump of the ECDF: F is Height from X up to the next step, Lower and Upper bound the true CDF there
+type Step struct {
+	X      float64
+	Height float64
+	Lower  float64
+	Upper  float64
+}
+
+func New(values interfaces.ISample) (ECDF, error) {
+	var data = values.GetValues()
+	if len(data) == 0 {
+		return ECDF{}, errors.New("ecdf: no values")
+	}
+	for _, v := range data {
+		if math.IsNaN(v) {
+			return ECDF{}, errors.New("ecdf: NaN value")
+		}
+	}
+	return ECDF{Values: sample.Sorted(data)}, nil
+}
+
+// every variant repeated by its occurences, e.g. a StatisticalDistribution
+func FromDistribution(d interfaces.IDistribution) (ECDF, error) {
+	var values = sample.Sample{}
+	var variants, occurences = d.GetVariants(), d.GetOccurences()
+	for i, x := range variants {
+		for j := 0; j < occurences[i]; j++ {
+			values = append(values, float64(x))
+		}
+	}
+	return New(values)
+}
+
+func (e ECDF) N() int {
+	return len(e.Values)
+}
+
+func (e ECDF) CDF(x float64) float64 {
+	return float64(sort.Search(len(e.Values), func(i int) bool { return e.Values[i] > x })) / float64(len(e.Values))
+}
+
+// the step-function inverse, the smallest value with F(value) ≥ p. p = 0 gives the minimum
+func (e ECDF) Quantile(p float64) float64 {
+	var k = int(math.Ceil(p*float64(len(e.Values)) - 1e-9))
+	if k < 1 {
+		k = 1
+	}
+	if k > len(e.Values) {
+		k = len(e.Values)
+	}
+	return e.Values[k-1]
+}
+
+// dvoretzky–kiefer–wolfowitz: the whole true CDF lies within ±ε of the ECDF with probability confidence,
+// ε = √(ln(2 / (1 − confidence)) / 2n)
+func (e ECDF) DKW(confidence float64) float64 {
+	return math.Sqrt(math.Log(2/(1-confidence)) / (2 * float64(len(e.Values))))
+}
+
+// the DKW band at x, clamped to [0, 1]
+func (e ECDF) Band(x, confidence float64) (float64, float64) {
+	var f, epsilon = e.CDF(x), e.DKW(confidence)
+	return math.Max(0, f-epsilon), math.Min(1, f+epsilon)
+}
+
+// one step per distinct value with its DKW band
+func (e ECDF) Steps(confidence float64) []Step {
+	var epsilon = e.DKW(confidence)
+	var n = float64(len(e.Values))
+
+	var steps = []Step{}
+	for i, v := range e.Values {
+		if i+1 < len(e.Values) && e.Values[i+1] == v {
+			continue
+		}
+		var height = float64(i+1) / n
+		steps = append(steps, Step{X: v, Height: height, Lower: math.Max(0, height-epsilon), Upper: math.Min(1, height+epsilon)})
+	}
+	return steps
+}
+
+// vertices of the staircase, ready for a line plot: from (min, 0) up every jump and along every tread,
+// the last tread runs one average step beyond the maximum
+func (e ECDF) Staircase() ([]float64, []float64) {
+	var steps = e.Steps(0.95)
+	var xs, ys = []float64{steps[0].X}, []float64{0}
+
+	var previous = 0.0
+	for i, s := range steps {
+		if i > 0 {
+			xs, ys = append(xs, s.X), append(ys, previous)
+		}
+		xs, ys = append(xs, s.X), append(ys, s.Height)
+		previous = s.Height
+	}
+
+	var tail = 1.0
+	if len(steps) > 1 {
+		tail = (steps[len(steps)-1].X - steps[0].X) / float64(len(steps)-1)
+	}
+	xs, ys = append(xs, steps[len(steps)-1].X+tail), append(ys, 1)
+
+	return xs, ys
+}
+
+func (e ECDF) WriteTable(w io.Writer, confidence float64) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintf(writer, " x\t F(x)\t %g%% lower\t %g%% upper\t\n", 100*confidence, 100*confidence)
+	for _, s := range e.Steps(confidence) {
+		fmt.Fprintf(writer, " %.4g\t %.4f\t %.4f\t %.4f\t\n", s.X, s.Height, s.Lower, s.Upper)
+	}
+
+	return writer.Flush()
+}
+
+// sup |Fa(x) − Fb(x)|, the two-sample kolmogorov–smirnov statistic
+func KolmogorovSmirnov(a, b ECDF) float64 {
+	var distance = 0.0
+	for _, values := range [][]float64{a.Values, b.Values} {
+		for _, x := range values {
+			distance = math.Max(distance, math.Abs(a.CDF(x)-b.CDF(x)))
+		}
+	}
+	return distance
+}
+
+// p-wasserstein distance (∫₀¹ |Qa(u) − Qb(u)|^p du)^(1/p), exact over the pieces where both quantile
+// functions are constant. p = 1 is the area between the two ECDFs
+func Wasserstein(a, b ECDF, p float64) (float64, error) {
+	if p < 1 {
+		return 0, fmt.Errorf("ecdf: wasserstein order %g is below 1", p)
+	}
+
+	var n, m = len(a.Values), len(b.Values)
+	var sum, u = 0.0, 0.0
+	var i, j = 0, 0
+	for i < n && j < m {
+		// the next jump of either quantile function
+		var nextA, nextB = float64(i+1) / float64(n), float64(j+1) / float64(m)
+		var next = math.Min(nextA, nextB)
+
+		sum += (next - u) * math.Pow(math.Abs(a.Values[i]-b.Values[j]), p)
+		u = next
+
+		if nextA <= next {
+			i++
+		}
+		if nextB <= next {
+			j++
+		}
+	}
+
+	return math.Pow(sum, 1/p), nil
+}
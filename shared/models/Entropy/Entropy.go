@@ -0,0 +1,252 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+)
+
+// base of the logarithm, the unit entropy is measured in
+type Base float64
+
+const (
+	Bits     Base = 2
+	Nats     Base = math.E
+	Hartleys Base = 10
+)
+
+func (b Base) String() string {
+	switch b {
+	case Bits:
+		return "bits"
+	case Nats:
+		return "nats"
+	case Hartleys:
+		return "hartleys"
+	}
+	return fmt.Sprintf("log%g units", float64(b))
+}
+
+// nats to this base
+func (b Base) from(nats float64) float64 {
+	return nats / math.Log(float64(b))
+}
+
+// entropy of one frequency table against the most it could be
+type Summary struct {
+	Base Base
+	N    int
+	// variants that occurred
+	Observed int
+	// variants that could occur, e.g. High − Low + 1 for Sequence.Random
+	Possible    int
+	Shannon     float64
+	MillerMadow float64
+	// log Possible, the entropy of the uniform distribution
+	Maximum float64
+	// Shannon / Maximum, 1 when every possible variant is equally frequent
+	Efficiency float64
+}
+
+// entropies of paired sequences x and y and what they share
+type Dependence struct {
+	Base Base
+	N    int
+	X    float64
+	Y    float64
+	// H(X, Y)
+	Joint float64
+	// H(X | Y) = H(X, Y) − H(Y)
+	XGivenY float64
+	// H(Y | X) = H(X, Y) − H(X)
+	YGivenX float64
+	// I(X; Y) = H(X) + H(Y) − H(X, Y)
+	Mutual float64
+	// I / √(H(X) H(Y)), between 0 for independence and 1 when either determines the other
+	Normalized float64
+	// I / H(Y), the share of the uncertainty about y that knowing x removes
+	Uncertainty float64
+}
+
+// H = −Σ p log p over the relative frequencies, variants that never occurred contribute nothing
+func Shannon(d interfaces.IDistribution, base Base) float64 {
+	var h, _, _ = entropy(d.GetOccurences())
+	return base.from(h)
+}
+
+// the plug-in H underestimates the entropy by about (m − 1) / 2n nats for m occurring variants out of n
+// observations, miller and madow add it back
+func MillerMadow(d interfaces.IDistribution, base Base) float64 {
+	var h, n, m = entropy(d.GetOccurences())
+	return base.from(millerMadow(h, n, m))
+}
+
+// Shannon over log possible, 0 when fewer than two variants are possible
+func Efficiency(d interfaces.IDistribution, possible int) float64 {
+	if possible < 2 {
+		return 0
+	}
+	var h, _, _ = entropy(d.GetOccurences())
+	return h / math.Log(float64(possible))
+}
+
+// every measure of one table, possible counts the variants that could have occurred
+func Summarize(d interfaces.IDistribution, possible int, base Base) (Summary, error) {
+	var h, n, m = entropy(d.GetOccurences())
+	if n == 0 {
+		return Summary{}, errors.New("entropy: the table is empty")
+	}
+	if possible < m {
+		return Summary{}, fmt.Errorf("entropy: %d variants occurred but only %d are possible", m, possible)
+	}
+
+	return Summary{
+		Base:        base,
+		N:           n,
+		Observed:    m,
+		Possible:    possible,
+		Shannon:     base.from(h),
+		MillerMadow: base.from(millerMadow(h, n, m)),
+		Maximum:     base.from(math.Log(float64(possible))),
+		Efficiency:  Efficiency(d, possible),
+	}, nil
+}
+
+// H(X, Y) over the pairs (x[i], y[i])
+func Joint(x, y []int, base Base) (float64, error) {
+	dependence, err := Pair(x, y, base)
+	return dependence.Joint, err
+}
+
+// H(X | Y), what is left to know about x once y is known
+func Conditional(x, y []int, base Base) (float64, error) {
+	dependence, err := Pair(x, y, base)
+	return dependence.XGivenY, err
+}
+
+// I(X; Y), 0 for independent sequences
+func MutualInformation(x, y []int, base Base) (float64, error) {
+	dependence, err := Pair(x, y, base)
+	return dependence.Mutual, err
+}
+
+// every measure between paired sequences. a sequence against itself shifted by one, Pair(s[:n-1], s[1:]),
+// measures how much each value tells about the next
+func Pair(x, y []int, base Base) (Dependence, error) {
+	if len(x) != len(y) {
+		return Dependence{}, fmt.Errorf("entropy: %d values cannot pair with %d", len(x), len(y))
+	}
+	if len(x) == 0 {
+		return Dependence{}, errors.New("entropy: no pairs")
+	}
+
+	var xs, ys = map[int]int{}, map[int]int{}
+	var pairs = map[[2]int]int{}
+	for i := range x {
+		xs[x[i]]++
+		ys[y[i]]++
+		pairs[[2]int{x[i], y[i]}]++
+	}
+
+	var hx, _, _ = entropy(values(xs))
+	var hy, _, _ = entropy(values(ys))
+	var hxy, _, _ = entropy(values(pairs))
+	// rounding can leave a hair below zero when x and y are independent in the sample
+	var mutual = math.Max(0, hx+hy-hxy)
+
+	var dependence = Dependence{
+		Base:    base,
+		N:       len(x),
+		X:       base.from(hx),
+		Y:       base.from(hy),
+		Joint:   base.from(hxy),
+		XGivenY: base.from(math.Max(0, hxy-hy)),
+		YGivenX: base.from(math.Max(0, hxy-hx)),
+		Mutual:  base.from(mutual),
+	}
+	if hx > 0 && hy > 0 {
+		dependence.Normalized = math.Min(1, mutual/math.Sqrt(hx*hy))
+	}
+	if hy > 0 {
+		dependence.Uncertainty = math.Min(1, mutual/hy)
+	}
+
+	return dependence, nil
+}
+
+func (s Summary) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintf(writer, " n\t %d\t\n", s.N)
+	fmt.Fprintf(writer, " Variants occurred / possible\t %d / %d\t\n", s.Observed, s.Possible)
+	fmt.Fprintf(writer, " Shannon entropy, %s\t %.4f\t\n", s.Base, s.Shannon)
+	fmt.Fprintf(writer, " Miller–Madow entropy, %s\t %.4f\t\n", s.Base, s.MillerMadow)
+	fmt.Fprintf(writer, " Maximum (uniform), %s\t %.4f\t\n", s.Base, s.Maximum)
+	fmt.Fprintf(writer, " Efficiency H / Hmax\t %.4f\t\n", s.Efficiency)
+
+	return writer.Flush()
+}
+
+func (d Dependence) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintf(writer, " Pairs\t %d\t\n", d.N)
+	fmt.Fprintf(writer, " H(X), %s\t %.4f\t\n", d.Base, d.X)
+	fmt.Fprintf(writer, " H(Y), %s\t %.4f\t\n", d.Base, d.Y)
+	fmt.Fprintf(writer, " H(X, Y), %s\t %.4f\t\n", d.Base, d.Joint)
+	fmt.Fprintf(writer, " H(X | Y), %s\t %.4f\t\n", d.Base, d.XGivenY)
+	fmt.Fprintf(writer, " H(Y | X), %s\t %.4f\t\n", d.Base, d.YGivenX)
+	fmt.Fprintf(writer, " I(X; Y), %s\t %.4f\t\n", d.Base, d.Mutual)
+	fmt.Fprintf(writer, " I / √(H(X) H(Y))\t %.4f\t\n", d.Normalized)
+	fmt.Fprintf(writer, " I / H(Y)\t %.4f\t\n", d.Uncertainty)
+
+	return writer.Flush()
+}
+
+// plug-in entropy in nats, the number of observations and of occurring variants
+func entropy(counts []int) (float64, int, int) {
+	var n, m = 0, 0
+	for _, c := range counts {
+		if c > 0 {
+			n += c
+			m++
+		}
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+
+	var h = 0.0
+	for _, c := range counts {
+		if c > 0 {
+			var p = float64(c) / float64(n)
+			h -= p * math.Log(p)
+		}
+	}
+	return math.Max(0, h), n, m
+}
+
+func millerMadow(h float64, n, m int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return h + float64(m-1)/float64(2*n)
+}
+
+func values[K comparable](counts map[K]int) []int {
+	var list = make([]int, 0, len(counts))
+	for _, c := range counts {
+		list = append(list, c)
+	}
+	// a fixed order keeps the sums identical from run to run
+	sort.Ints(list)
+	return list
+}
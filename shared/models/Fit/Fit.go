@@ -0,0 +1,820 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	distribution "shared/models/Distribution"
+	optimize "shared/models/Optimize"
+	sample "shared/models/Sample"
+)
+
+// a fitted distribution. it satisfies GoodnessOfFit.Fitted, so a fit can go straight into ParametricBootstrap
+type Model interface {
+	PDF(x float64) float64
+	CDF(x float64) float64
+	Rand(rnd *rand.Rand) float64
+}
+
+type Parameter struct {
+	Name     string
+	Value    float64
+	StdError float64
+}
+
+type Fit struct {
+	Family        string
+	Method        string
+	Parameters    []Parameter
+	N             int
+	LogLikelihood float64
+	AIC           float64
+	BIC           float64
+	Model         Model
+}
+
+// a parametric family and how to estimate it. parameters are always in the order of Parameters
+type Family struct {
+	Name       string
+	Parameters []string
+	// discrete families have probabilities instead of densities, their likelihoods do not compare with continuous ones
+	Discrete bool
+	// nil when every value lies in the support
+	Support func(values []float64) error
+	Make    func(params []float64) (Model, error)
+	// closed-form maximum likelihood estimates, nil means numerical maximisation from Start
+	Closed func(values []float64) ([]float64, error)
+	// starting point of the numerical maximisation when there is no closed form
+	Start func(values []float64) []float64
+	// parameters that must stay positive, the maximisation searches them on the log scale
+	Positive []bool
+	// standard errors of the closed-form estimates from the expected fisher information,
+	// nil means the observed information (numerical hessian of the log-likelihood)
+	StdErrors func(params []float64, n int) []float64
+	// the support depends on the parameters and there is no fisher information. MLERounded then
+	// applies Closed to the interval ends instead of maximising, and keeps StdErrors
+	Irregular bool
+	// method of moments estimates from the mean and the variance (divided by n), nil when there are none
+	Moments func(mean, variance float64) ([]float64, error)
+}
+
+type Criterion int
+
+const (
+	AIC Criterion = iota
+	BIC
+)
+
+func (c Criterion) String() string {
+	if c == BIC {
+		return "BIC"
+	}
+	return "AIC"
+}
+
+// a family AutoFit could not fit, with the reason
+type Skipped struct {
+	Family string
+	Err    error
+}
+
+type Ranking struct {
+	Criterion Criterion
+	// best first
+	Fits    []Fit
+	Skipped []Skipped
+}
+
+const MaximumLikelihood = "maximum likelihood"
+
+// doubling and bisection steps of the binomial search for n, both take about log₂ n
+const BinomialSearchLimit = 200
+
+var Normal = Family{
+	Name:       "normal",
+	Parameters: []string{"mu", "sigma"},
+	Positive:   []bool{false, true},
+	Support:    varying,
+	Make: func(p []float64) (Model, error) {
+		if p[1] <= 0 {
+			return nil, errors.New("normal: sigma must be positive")
+		}
+		return distribution.Normal{Mu: p[0], Sigma: p[1]}, nil
+	},
+	Closed: func(values []float64) ([]float64, error) {
+		return []float64{sample.Mean(values), plugInDeviation(values)}, nil
+	},
+	StdErrors: func(p []float64, n int) []float64 {
+		var m = float64(n)
+		return []float64{p[1] / math.Sqrt(m), p[1] / math.Sqrt(2*m)}
+	},
+	Moments: func(mean, variance float64) ([]float64, error) {
+		return []float64{mean, math.Sqrt(variance)}, spread(variance)
+	},
+}
+
+var Exponential = Family{
+	Name:       "exponential",
+	Parameters: []string{"rate"},
+	Positive:   []bool{true},
+	Support: func(values []float64) error {
+		if err := nonNegative(values); err != nil {
+			return err
+		}
+		if sample.Mean(values) == 0 {
+			return errors.New("all values are zero")
+		}
+		return nil
+	},
+	Make: func(p []float64) (Model, error) {
+		if p[0] <= 0 {
+			return nil, errors.New("exponential: rate must be positive")
+		}
+		return distribution.Exponential{Rate: p[0]}, nil
+	},
+	Closed: func(values []float64) ([]float64, error) {
+		return []float64{1 / sample.Mean(values)}, nil
+	},
+	StdErrors: func(p []float64, n int) []float64 {
+		return []float64{p[0] / math.Sqrt(float64(n))}
+	},
+	Moments: func(mean, variance float64) ([]float64, error) {
+		if mean <= 0 {
+			return nil, errors.New("the mean must be positive")
+		}
+		return []float64{1 / mean}, nil
+	},
+}
+
+// the likelihood is not regular at the endpoints, so there is no fisher information.
+// the standard errors are the exact deviations of the sample minimum and maximum
+var Uniform = Family{
+	Name:       "uniform",
+	Parameters: []string{"low", "high"},
+	Positive:   []bool{false, false},
+	Irregular:  true,
+	Support:    varying,
+	Make: func(p []float64) (Model, error) {
+		if p[1] <= p[0] {
+			return nil, errors.New("uniform: high must exceed low")
+		}
+		return distribution.Uniform{Low: p[0], High: p[1]}, nil
+	},
+	Closed: func(values []float64) ([]float64, error) {
+		var sorted = sample.Sorted(values)
+		return []float64{sorted[0], sorted[len(sorted)-1]}, nil
+	},
+	StdErrors: func(p []float64, n int) []float64 {
+		var m = float64(n)
+		var se = (p[1] - p[0]) * math.Sqrt(m/(m+2)) / (m + 1)
+		return []float64{se, se}
+	},
+	// mean ∓ √3 σ
+	Moments: func(mean, variance float64) ([]float64, error) {
+		var half = math.Sqrt(3 * variance)
+		return []float64{mean - half, mean + half}, spread(variance)
+	},
+}
+
+var LogNormal = Family{
+	Name:       "log-normal",
+	Parameters: []string{"mu", "sigma"},
+	Positive:   []bool{false, true},
+	Support: func(values []float64) error {
+		if err := positive(values); err != nil {
+			return err
+		}
+		return varying(values)
+	},
+	Make: func(p []float64) (Model, error) {
+		if p[1] <= 0 {
+			return nil, errors.New("log-normal: sigma must be positive")
+		}
+		return distribution.LogNormal{Mu: p[0], Sigma: p[1]}, nil
+	},
+	Closed: func(values []float64) ([]float64, error) {
+		var logs = make([]float64, len(values))
+		for i, v := range values {
+			logs[i] = math.Log(v)
+		}
+		return []float64{sample.Mean(logs), plugInDeviation(logs)}, nil
+	},
+	StdErrors: func(p []float64, n int) []float64 {
+		var m = float64(n)
+		return []float64{p[1] / math.Sqrt(m), p[1] / math.Sqrt(2*m)}
+	},
+	// σ² = ln(1 + variance/mean²), μ = ln mean − σ²/2
+	Moments: func(mean, variance float64) ([]float64, error) {
+		if mean <= 0 {
+			return nil, errors.New("the mean must be positive")
+		}
+		var s2 = math.Log1p(variance / (mean * mean))
+		return []float64{math.Log(mean) - s2/2, math.Sqrt(s2)}, spread(variance)
+	},
+}
+
+var Gamma = Family{
+	Name:       "gamma",
+	Parameters: []string{"shape", "scale"},
+	Positive:   []bool{true, true},
+	Support: func(values []float64) error {
+		if err := positive(values); err != nil {
+			return err
+		}
+		return varying(values)
+	},
+	Make: func(p []float64) (Model, error) {
+		if p[0] <= 0 || p[1] <= 0 {
+			return nil, errors.New("gamma: shape and scale must be positive")
+		}
+		return distribution.Gamma{Shape: p[0], Scale: p[1]}, nil
+	},
+	// method of moments
+	Start: func(values []float64) []float64 {
+		var mean, variance = sample.Mean(values), sample.Variance(values)
+		return []float64{mean * mean / variance, variance / mean}
+	},
+	// shape mean²/variance, scale variance/mean
+	Moments: func(mean, variance float64) ([]float64, error) {
+		if mean <= 0 {
+			return nil, errors.New("the mean must be positive")
+		}
+		return []float64{mean * mean / variance, variance / mean}, spread(variance)
+	},
+}
+
+var Weibull = Family{
+	Name:       "weibull",
+	Parameters: []string{"shape", "scale"},
+	Positive:   []bool{true, true},
+	Support: func(values []float64) error {
+		if err := positive(values); err != nil {
+			return err
+		}
+		return varying(values)
+	},
+	Make: func(p []float64) (Model, error) {
+		if p[0] <= 0 || p[1] <= 0 {
+			return nil, errors.New("weibull: shape and scale must be positive")
+		}
+		return distribution.Weibull{Shape: p[0], Scale: p[1]}, nil
+	},
+	// shape from the coefficient of variation (k ≈ cv^−1.086), scale from the mean
+	Start: func(values []float64) []float64 {
+		var mean = sample.Mean(values)
+		var shape = math.Pow(sample.StdDev(values)/mean, -1.086)
+		return []float64{shape, mean / math.Gamma(1+1/shape)}
+	},
+}
+
+var Poisson = Family{
+	Name:       "poisson",
+	Parameters: []string{"lambda"},
+	Positive:   []bool{true},
+	Discrete:   true,
+	Support:    whole,
+	Make: func(p []float64) (Model, error) {
+		if p[0] < 0 {
+			return nil, errors.New("poisson: lambda must be non-negative")
+		}
+		var d = distribution.Poisson{Lambda: p[0]}
+		return counts{pmf: d.PMF, cdf: d.CDF, draw: d.Rand}, nil
+	},
+	Closed: func(values []float64) ([]float64, error) {
+		return []float64{sample.Mean(values)}, nil
+	},
+	StdErrors: func(p []float64, n int) []float64 {
+		return []float64{math.Sqrt(p[0] / float64(n))}
+	},
+	Moments: func(mean, variance float64) ([]float64, error) {
+		return []float64{mean}, nil
+	},
+}
+
+// both the number of trials and the success probability unknown. the maximum likelihood n is finite
+// only when the variance (divided by n) is below the mean, so Support asks for that. n is discrete and
+// has no standard error, the one of p treats n as known. the moment estimate of n is not an integer,
+// its model rounds it
+var Binomial = Family{
+	Name:       "binomial",
+	Parameters: []string{"n", "p"},
+	Positive:   []bool{true, false},
+	Discrete:   true,
+	Support: func(values []float64) error {
+		if err := whole(values); err != nil {
+			return err
+		}
+		var mean = sample.Mean(values)
+		if plugInDeviation(values)*plugInDeviation(values) >= mean {
+			return errors.New("the variance is not below the mean")
+		}
+		return nil
+	},
+	Make: func(p []float64) (Model, error) {
+		var trials = int(math.Round(p[0]))
+		if trials < 1 || p[1] < 0 || p[1] > 1 {
+			return nil, errors.New("binomial: n must be at least 1 and p within [0, 1]")
+		}
+		var d = distribution.Binomial{N: trials, P: p[1]}
+		return counts{pmf: d.PMF, cdf: d.CDF, draw: d.Rand}, nil
+	},
+	// profile likelihood over n ≥ the largest value, p = mean/n. it rises and then falls, so the search
+	// starts at the moment estimate, brackets the peak by doubling and bisects on the sign of the slope
+	Closed: func(values []float64) ([]float64, error) {
+		var mean = sample.Mean(values)
+		var profile = func(n int) float64 {
+			var d = distribution.Binomial{N: n, P: mean / float64(n)}
+			var sum = 0.0
+			for _, v := range values {
+				sum += math.Log(d.PMF(int(v)))
+			}
+			return sum
+		}
+		var rising = func(n int) bool {
+			return profile(n+1) > profile(n)
+		}
+
+		var lowest = max(1, int(sample.Sorted(values)[len(values)-1]))
+		var variance = plugInDeviation(values) * plugInDeviation(values)
+		var start = lowest
+		if moment := math.Round(mean * mean / (mean - variance)); moment > float64(lowest) && moment < math.MaxInt32 {
+			start = int(moment)
+		}
+
+		// the peak is the first n in [lo, hi] where the profile stops rising
+		var lo, hi = lowest, start
+		var steps = 0
+		if rising(start) {
+			lo = start + 1
+			for hi = 2 * start; rising(hi); hi *= 2 {
+				if steps++; hi > math.MaxInt32/2 || steps > BinomialSearchLimit {
+					return nil, fmt.Errorf("binomial: the profile likelihood still rises at n = %d", hi)
+				}
+				lo = hi + 1
+			}
+		}
+		for lo < hi {
+			if steps++; steps > BinomialSearchLimit {
+				return nil, errors.New("binomial: the search for n did not converge")
+			}
+			var mid = lo + (hi-lo)/2
+			if rising(mid) {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		return []float64{float64(hi), mean / float64(hi)}, nil
+	},
+	StdErrors: func(p []float64, n int) []float64 {
+		return []float64{math.NaN(), math.Sqrt(p[1] * (1 - p[1]) / (p[0] * float64(n)))}
+	},
+	// n = mean²/(mean − variance), p = 1 − variance/mean
+	Moments: func(mean, variance float64) ([]float64, error) {
+		if variance >= mean {
+			return nil, errors.New("the variance is not below the mean")
+		}
+		return []float64{mean * mean / (mean - variance), 1 - variance/mean}, nil
+	},
+}
+
+// the continuous families AutoFit tries by default
+func Continuous() []Family {
+	return []Family{Normal, Exponential, Uniform, LogNormal, Gamma, Weibull}
+}
+
+func MLE(values interfaces.ISample, family Family) (Fit, error) {
+	var data = values.GetValues()
+	if err := check(family, data); err != nil {
+		return Fit{}, err
+	}
+
+	var negLogLikelihood = func(p []float64) float64 {
+		model, err := family.Make(p)
+		if err != nil {
+			return math.Inf(1)
+		}
+		return -logLikelihood(model, data)
+	}
+
+	var params []float64
+	var err error
+	if family.Closed != nil {
+		if params, err = family.Closed(data); err != nil {
+			return Fit{}, err
+		}
+	} else {
+		if params, err = maximize(family, negLogLikelihood, family.Start(data)); err != nil {
+			return Fit{}, err
+		}
+	}
+
+	var stdErrors []float64
+	if family.StdErrors != nil {
+		stdErrors = family.StdErrors(params, len(data))
+	} else {
+		stdErrors = observedStdErrors(optimize.Hessian(negLogLikelihood, params))
+	}
+
+	return complete(family, MaximumLikelihood, params, stdErrors, len(data), -negLogLikelihood(params))
+}
+
+// maximum likelihood for values rounded to multiples of precision (lb5's integers have precision 1).
+// a value x only says the observation fell in [x − precision/2, x + precision/2), so its likelihood is
+// F(x + precision/2) − F(x − precision/2) instead of a density. point densities would favour any family
+// that piles mass on the few distinct values, e.g. a uniform ending exactly at the extremes
+func MLERounded(values interfaces.ISample, family Family, precision float64) (Fit, error) {
+	if family.Discrete {
+		return Fit{}, fmt.Errorf("%s: discrete families are not rounded", family.Name)
+	}
+	if precision <= 0 {
+		return Fit{}, errors.New("rounded fit: precision must be positive")
+	}
+
+	var data = values.GetValues()
+	var half = precision / 2
+
+	// the support only has to reach into every interval
+	var upper = make([]float64, len(data))
+	for i, v := range data {
+		upper[i] = v + half
+	}
+	if err := check(family, upper); err != nil {
+		return Fit{}, err
+	}
+
+	var negLogLikelihood = func(p []float64) float64 {
+		model, err := family.Make(p)
+		if err != nil {
+			return math.Inf(1)
+		}
+		var sum = 0.0
+		for _, v := range data {
+			sum += math.Log(model.CDF(v+half) - model.CDF(v-half))
+		}
+		return -sum
+	}
+
+	var params, stdErrors []float64
+	if family.Irregular {
+		var ends = append([]float64{}, upper...)
+		for _, v := range data {
+			ends = append(ends, v-half)
+		}
+		var err error
+		if params, err = family.Closed(ends); err != nil {
+			return Fit{}, err
+		}
+		stdErrors = family.StdErrors(params, len(data))
+	} else {
+		// the point estimates are close, start from them
+		var start []float64
+		var err error
+		if family.Closed != nil {
+			if start, err = family.Closed(upper); err != nil {
+				return Fit{}, err
+			}
+		} else {
+			start = family.Start(upper)
+		}
+
+		if params, err = maximize(family, negLogLikelihood, start); err != nil {
+			return Fit{}, err
+		}
+		stdErrors = observedStdErrors(optimize.Hessian(negLogLikelihood, params))
+	}
+
+	var method = fmt.Sprintf("%s, rounded to %g", MaximumLikelihood, precision)
+	return complete(family, method, params, stdErrors, len(data), -negLogLikelihood(params))
+}
+
+// fits every family and ranks them by criterion, lowest first. families that cannot
+// describe the sample (e.g. negative values for log-normal) are reported in Skipped
+func AutoFit(values interfaces.ISample, criterion Criterion, families ...Family) (Ranking, error) {
+	return rank(criterion, families, func(family Family) (Fit, error) {
+		return MLE(values, family)
+	})
+}
+
+// AutoFit with MLERounded
+func AutoFitRounded(values interfaces.ISample, precision float64, criterion Criterion, families ...Family) (Ranking, error) {
+	return rank(criterion, families, func(family Family) (Fit, error) {
+		return MLERounded(values, family, precision)
+	})
+}
+
+func rank(criterion Criterion, families []Family, estimate func(family Family) (Fit, error)) (Ranking, error) {
+	if len(families) == 0 {
+		families = Continuous()
+	}
+	for _, f := range families {
+		if f.Discrete != families[0].Discrete {
+			return Ranking{}, errors.New("auto fit: discrete and continuous likelihoods cannot be ranked together")
+		}
+	}
+
+	var ranking = Ranking{Criterion: criterion}
+	for _, family := range families {
+		fit, err := estimate(family)
+		if err != nil {
+			ranking.Skipped = append(ranking.Skipped, Skipped{Family: family.Name, Err: err})
+			continue
+		}
+		ranking.Fits = append(ranking.Fits, fit)
+	}
+
+	sort.SliceStable(ranking.Fits, func(i, j int) bool {
+		return ranking.Fits[i].Score(criterion) < ranking.Fits[j].Score(criterion)
+	})
+
+	if len(ranking.Fits) == 0 {
+		return ranking, errors.New("auto fit: no candidate could be fitted")
+	}
+
+	return ranking, nil
+}
+
+func (f Fit) Score(criterion Criterion) float64 {
+	if criterion == BIC {
+		return f.BIC
+	}
+	return f.AIC
+}
+
+func (f Fit) Value(name string) float64 {
+	for _, p := range f.Parameters {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return math.NaN()
+}
+
+func (r Ranking) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	var best = 0.0
+	if len(r.Fits) > 0 {
+		best = r.Fits[0].Score(r.Criterion)
+	}
+
+	fmt.Fprintf(writer, " Family\t Parameters (± std. error)\t log L\t AIC\t BIC\t Δ%s\t\n", r.Criterion)
+	for _, f := range r.Fits {
+		fmt.Fprintf(writer, " %s\t %s\t %.4f\t %.4f\t %.4f\t %.4f\t\n", f.Family, f.describe(), f.LogLikelihood, f.AIC, f.BIC, f.Score(r.Criterion)-best)
+	}
+	writer.Flush()
+
+	for _, s := range r.Skipped {
+		fmt.Fprintf(w, "Skipped %s\n", s.Err)
+	}
+
+	return nil
+}
+
+// several estimates side by side, e.g. the MLE and MME fits of one sample
+func WriteFits(w io.Writer, fits ...Fit) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Family\t Method\t Parameters (± std. error)\t log L\t")
+	for _, f := range fits {
+		fmt.Fprintf(writer, " %s\t %s\t %s\t %.4f\t\n", f.Family, f.Method, f.describe(), f.LogLikelihood)
+	}
+
+	return writer.Flush()
+}
+
+func (f Fit) describe() string {
+	var text = ""
+	for i, p := range f.Parameters {
+		if i > 0 {
+			text += ", "
+		}
+		text += fmt.Sprintf("%s = %.4g", p.Name, p.Value)
+		if !math.IsNaN(p.StdError) {
+			text += fmt.Sprintf(" ± %.2g", p.StdError)
+		}
+	}
+	return text
+}
+
+// fills in the information criteria of estimated parameters
+func complete(family Family, method string, params, stdErrors []float64, size int, logLikelihood float64) (Fit, error) {
+	model, err := family.Make(params)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	var fit = Fit{Family: family.Name, Method: method, N: size, Model: model, LogLikelihood: logLikelihood}
+	for i, name := range family.Parameters {
+		fit.Parameters = append(fit.Parameters, Parameter{Name: name, Value: params[i], StdError: stdErrors[i]})
+	}
+
+	var k, n = float64(len(params)), float64(size)
+	fit.AIC = 2*k - 2*fit.LogLikelihood
+	fit.BIC = k*math.Log(n) - 2*fit.LogLikelihood
+
+	return fit, nil
+}
+
+func check(family Family, data []float64) error {
+	if len(data) < 2 {
+		return fmt.Errorf("%s: at least two values are needed", family.Name)
+	}
+	if family.Support != nil {
+		if err := family.Support(data); err != nil {
+			return fmt.Errorf("%s: %v", family.Name, err)
+		}
+	}
+	return nil
+}
+
+// nelder–mead on the negative log-likelihood, Positive parameters on the log scale
+func maximize(family Family, negLogLikelihood func(p []float64) float64, start []float64) ([]float64, error) {
+	var toParams = func(x []float64) []float64 {
+		var p = make([]float64, len(x))
+		for i, v := range x {
+			p[i] = v
+			if family.Positive[i] {
+				p[i] = math.Exp(v)
+			}
+		}
+		return p
+	}
+
+	var x = make([]float64, len(start))
+	for i, v := range start {
+		x[i] = v
+		if family.Positive[i] {
+			x[i] = math.Log(v)
+		}
+	}
+
+	var result = optimize.NelderMead(func(x []float64) float64 {
+		return negLogLikelihood(toParams(x))
+	}, x, optimize.DefaultSettings())
+	if !result.Converged {
+		return nil, fmt.Errorf("%s: the likelihood maximisation did not converge", family.Name)
+	}
+
+	return toParams(result.X), nil
+}
+
+func logLikelihood(model Model, data []float64) float64 {
+	var sum = 0.0
+	for _, v := range data {
+		sum += math.Log(model.PDF(v))
+	}
+	return sum
+}
+
+// square roots of the diagonal of the inverted information matrix, NaN when it is singular
+func observedStdErrors(information [][]float64) []float64 {
+	var n = len(information)
+	var stdErrors = make([]float64, n)
+	var covariance, ok = invert(information)
+
+	for i := range stdErrors {
+		stdErrors[i] = math.NaN()
+		if ok && covariance[i][i] > 0 {
+			stdErrors[i] = math.Sqrt(covariance[i][i])
+		}
+	}
+
+	return stdErrors
+}
+
+// gauss–jordan with partial pivoting
+func invert(matrix [][]float64) ([][]float64, bool) {
+	var n = len(matrix)
+	var a = make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, 2*n)
+		copy(a[i], matrix[i])
+		a[i][n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		var pivot = col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if a[pivot][col] == 0 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		var scale = a[col][col]
+		for j := range a[col] {
+			a[col][j] /= scale
+		}
+		for row := 0; row < n; row++ {
+			if row == col {
+				continue
+			}
+			var factor = a[row][col]
+			for j := range a[row] {
+				a[row][j] -= factor * a[col][j]
+			}
+		}
+	}
+
+	var inverse = make([][]float64, n)
+	for i := range inverse {
+		inverse[i] = a[i][n:]
+	}
+	return inverse, true
+}
+
+// the maximum likelihood deviation divides by n
+func plugInDeviation(values []float64) float64 {
+	var n = float64(len(values))
+	return math.Sqrt(sample.Variance(values) * (n - 1) / n)
+}
+
+func varying(values []float64) error {
+	for _, v := range values {
+		if v != values[0] {
+			return nil
+		}
+	}
+	return errors.New("all values are equal")
+}
+
+func positive(values []float64) error {
+	for _, v := range values {
+		if v <= 0 {
+			return fmt.Errorf("needs positive values, got %g", v)
+		}
+	}
+	return nil
+}
+
+// non-negative integers
+func whole(values []float64) error {
+	if err := nonNegative(values); err != nil {
+		return err
+	}
+	for _, v := range values {
+		if v != math.Trunc(v) {
+			return fmt.Errorf("%g is not a count", v)
+		}
+	}
+	return nil
+}
+
+// moment estimates that need the values to spread
+func spread(variance float64) error {
+	if variance <= 0 {
+		return errors.New("the variance is zero")
+	}
+	return nil
+}
+
+func nonNegative(values []float64) error {
+	for _, v := range values {
+		if v < 0 {
+			return fmt.Errorf("needs non-negative values, got %g", v)
+		}
+	}
+	return nil
+}
+
+// probabilities of a count distribution seen as a model over float values, non-integers have probability zero
+type counts struct {
+	pmf  func(k int) float64
+	cdf  func(k int) float64
+	draw func(rnd *rand.Rand) int
+}
+
+func (c counts) PDF(x float64) float64 {
+	if x != math.Trunc(x) || math.IsInf(x, 0) {
+		return 0
+	}
+	return c.pmf(int(x))
+}
+
+func (c counts) CDF(x float64) float64 {
+	if x >= math.MaxInt32 {
+		return 1
+	}
+	if x < 0 {
+		return 0
+	}
+	return c.cdf(int(math.Floor(x)))
+}
+
+func (c counts) Rand(rnd *rand.Rand) float64 {
+	return float64(c.draw(rnd))
+}
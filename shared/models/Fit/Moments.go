@@ -0,0 +1,141 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"shared/interfaces"
+
+	grouped "shared/models/Grouped"
+	sample "shared/models/Sample"
+)
+
+const MethodOfMoments = "method of moments"
+
+// method of moments: the parameters whose mean and variance equal the sample's (divided by n).
+// the log-likelihood is the one MLE maximises, so the two fits of a sample compare directly
+func MME(values interfaces.ISample, family Family) (Fit, error) {
+	var data = values.GetValues()
+	if err := check(family, data); err != nil {
+		return Fit{}, err
+	}
+
+	var mean = sample.Mean(data)
+	var moment = func(k float64) float64 {
+		var sum = 0.0
+		for _, v := range data {
+			sum += math.Pow(v-mean, k)
+		}
+		return sum / float64(len(data))
+	}
+
+	var moments = grouped.Moments{N: len(data), Mean: mean, Variance: moment(2), Third: moment(3), Fourth: moment(4)}
+	params, stdErrors, err := momentEstimates(family, moments)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	model, err := family.Make(params)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	return complete(family, MethodOfMoments, params, stdErrors, len(data), logLikelihood(model, data))
+}
+
+// method of moments from a frequency table, every observation at the midpoint of its class. sheppard
+// corrects the variance for the grouping (see Grouped.Table.Moments). the log-likelihood is that of the
+// table, Σ f log(F(upper) − F(lower)), it only compares with other fits of the same table
+func MMEGrouped(table grouped.Table, family Family, sheppard bool) (Fit, error) {
+	var midpoints = []float64{}
+	for _, c := range table {
+		if c.Frequency > 0 {
+			midpoints = append(midpoints, c.Midpoint())
+		}
+	}
+	if err := check(family, midpoints); err != nil {
+		return Fit{}, err
+	}
+
+	moments, err := table.Moments(sheppard)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	params, stdErrors, err := momentEstimates(family, moments)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	model, err := family.Make(params)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	var logLikelihood = 0.0
+	for _, c := range table {
+		if c.Frequency > 0 {
+			logLikelihood += float64(c.Frequency) * math.Log(model.CDF(c.Upper)-model.CDF(c.Lower))
+		}
+	}
+
+	var method = MethodOfMoments + ", grouped"
+	if sheppard {
+		method += ", sheppard's correction"
+	}
+
+	return complete(family, method, params, stdErrors, moments.N, logLikelihood)
+}
+
+// the family's estimates and their delta-method standard errors. the sample mean m and variance v
+// have var(m) = μ2/n, cov(m, v) = μ3/n and var(v) = (μ4 − μ2²)/n, the gradient is numerical
+func momentEstimates(family Family, m grouped.Moments) ([]float64, []float64, error) {
+	if family.Moments == nil {
+		return nil, nil, fmt.Errorf("%s: no method of moments estimator", family.Name)
+	}
+
+	params, err := family.Moments(m.Mean, m.Variance)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", family.Name, err)
+	}
+
+	var n = float64(m.N)
+	var covariance = [2][2]float64{
+		{m.Variance / n, m.Third / n},
+		{m.Third / n, (m.Fourth - m.Variance*m.Variance) / n},
+	}
+
+	var at = [2]float64{m.Mean, m.Variance}
+	var steps = [2]float64{
+		1e-6 * math.Max(math.Max(math.Abs(m.Mean), math.Sqrt(m.Variance)), 1e-8),
+		1e-6 * math.Max(m.Variance, 1e-8),
+	}
+
+	var gradient = make([][2]float64, len(params))
+	for j := range at {
+		var plus, minus = at, at
+		plus[j] += steps[j]
+		minus[j] -= steps[j]
+
+		up, errUp := family.Moments(plus[0], plus[1])
+		down, errDown := family.Moments(minus[0], minus[1])
+		for i := range params {
+			gradient[i][j] = math.NaN()
+			if errUp == nil && errDown == nil {
+				gradient[i][j] = (up[i] - down[i]) / (2 * steps[j])
+			}
+		}
+	}
+
+	var stdErrors = make([]float64, len(params))
+	for i, g := range gradient {
+		var variance = 0.0
+		for a := range g {
+			for b := range g {
+				variance += g[a] * covariance[a][b] * g[b]
+			}
+		}
+		stdErrors[i] = math.Sqrt(variance)
+	}
+
+	return params, stdErrors, nil
+}
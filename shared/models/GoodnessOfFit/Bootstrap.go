@@ -0,0 +1,182 @@
+package shared
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"shared/interfaces"
+	"sort"
+
+	distribution "shared/models/Distribution"
+	sample "shared/models/Sample"
+	simulation "shared/models/Simulation"
+)
+
+// a distribution fitted to data, distribution.Normal and distribution.Exponential qualify
+type Fitted interface {
+	CDF(x float64) float64
+	Rand(rnd *rand.Rand) float64
+}
+
+// estimates the hypothesised family from values. the bootstrap refits every simulated sample,
+// so it has to be the same procedure that produced the fit under test
+type Fitter func(values []float64) (Fitted, error)
+
+// discrepancy between values and a fitted distribution, larger means a worse fit
+type Statistic func(values []float64, fitted Fitted) float64
+
+type BootstrapResult struct {
+	Statistic float64
+	PValue    float64
+	// monte carlo standard error of PValue
+	MCSE         float64
+	Replications int
+	// simulated samples the fitter gave up on, they are left out of the null distribution
+	Failed int
+	// ascending statistics of the simulated samples
+	Null []float64
+	Fit  Fitted
+}
+
+// normal with the sample mean and (n-1) standard deviation
+func FitNormal(values []float64) (Fitted, error) {
+	if len(values) < 2 {
+		return nil, errors.New("fit normal: at least two values are needed")
+	}
+
+	var sd = sample.StdDev(values)
+	if sd == 0 {
+		return nil, errors.New("fit normal: all values are equal")
+	}
+
+	return distribution.Normal{Mu: sample.Mean(values), Sigma: sd}, nil
+}
+
+// exponential with rate 1 / sample mean
+func FitExponential(values []float64) (Fitted, error) {
+	if len(values) == 0 {
+		return nil, errors.New("fit exponential: no values")
+	}
+
+	var mean = sample.Mean(values)
+	if mean <= 0 {
+		return nil, errors.New("fit exponential: the mean must be positive")
+	}
+
+	return distribution.Exponential{Rate: 1 / mean}, nil
+}
+
+// kolmogorov–smirnov D = sup |F_n(x) − F(x)|
+func KolmogorovSmirnov(values []float64, fitted Fitted) float64 {
+	var u = probabilities(values, fitted)
+	var n = float64(len(u))
+	var d = 0.0
+
+	for i, p := range u {
+		d = math.Max(d, math.Max(float64(i+1)/n-p, p-float64(i)/n))
+	}
+
+	return d
+}
+
+// cramér–von mises W² = 1/(12n) + Σ (F(x_(i)) − (2i−1)/(2n))²
+func CramerVonMises(values []float64, fitted Fitted) float64 {
+	var u = probabilities(values, fitted)
+	var n = float64(len(u))
+	var w = 1 / (12 * n)
+
+	for i, p := range u {
+		var diff = p - float64(2*i+1)/(2*n)
+		w += diff * diff
+	}
+
+	return w
+}
+
+// anderson–darling A², weighs the tails more than W²
+func AndersonDarling(values []float64, fitted Fitted) float64 {
+	var u = probabilities(values, fitted)
+	var n = len(u)
+	var sum = 0.0
+
+	for i := range u {
+		// keep the logarithms finite when a value sits in the far tail of the fit
+		var low = math.Max(u[i], 1e-300)
+		var high = math.Max(1-u[n-1-i], 1e-300)
+		sum += float64(2*i+1) * (math.Log(low) + math.Log(high))
+	}
+
+	return -float64(n) - sum/float64(n)
+}
+
+// p-value of statistic for the fit of values, with the null distribution simulated from the fitted model.
+// every replicate draws len(values) points from the fit, refits them and recomputes the statistic,
+// so estimating the parameters from the same data is accounted for
+func ParametricBootstrap(values interfaces.ISample, fit Fitter, statistic Statistic, config simulation.Config) (BootstrapResult, error) {
+	var data = values.GetValues()
+	fitted, err := fit(data)
+	if err != nil {
+		return BootstrapResult{}, err
+	}
+	if config.Replications <= 0 {
+		return BootstrapResult{}, errors.New("bootstrap: at least one replication is needed")
+	}
+
+	var result = BootstrapResult{Statistic: statistic(data, fitted), Fit: fitted}
+
+	simulated, err := simulation.Replicate(config, func(rnd *rand.Rand) float64 {
+		var draw = make([]float64, len(data))
+		for i := range draw {
+			draw[i] = fitted.Rand(rnd)
+		}
+
+		refitted, err := fit(draw)
+		if err != nil {
+			return math.NaN()
+		}
+		return statistic(draw, refitted)
+	})
+	if err != nil {
+		return BootstrapResult{}, err
+	}
+
+	var exceed = 0
+	for _, s := range simulated {
+		if math.IsNaN(s) {
+			result.Failed++
+			continue
+		}
+		result.Null = append(result.Null, s)
+		if s >= result.Statistic {
+			exceed++
+		}
+	}
+	sort.Float64s(result.Null)
+
+	result.Replications = len(result.Null)
+	if result.Replications == 0 {
+		return result, errors.New("bootstrap: every simulated sample failed to fit")
+	}
+
+	// the observed sample counts as one draw from the null, so the p-value is never zero
+	var b = float64(result.Replications)
+	result.PValue = (1 + float64(exceed)) / (b + 1)
+	result.MCSE = math.Sqrt(result.PValue * (1 - result.PValue) / b)
+
+	return result, nil
+}
+
+// simulated critical value, the 1 − alpha quantile of the null distribution
+func (r BootstrapResult) Critical(alpha float64) float64 {
+	var b = len(r.Null)
+	var idx = int(math.Ceil((1-alpha)*float64(b+1))) - 1
+	return r.Null[max(0, min(idx, b-1))]
+}
+
+func probabilities(values []float64, fitted Fitted) []float64 {
+	var u = sample.Sorted(values)
+	for i, v := range u {
+		u[i] = fitted.CDF(v)
+	}
+	return u
+}
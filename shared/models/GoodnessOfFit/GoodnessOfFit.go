@@ -0,0 +1,388 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"shared/interfaces"
+	"sort"
+
+	contingency "shared/models/Contingency"
+	distribution "shared/models/Distribution"
+)
+
+// lb5's rule of thumb
+const DefaultMinimum = 5
+
+// what merging looks at: the observed counts (lb5's original behavior) or the counts expected under H0 (the textbook rule)
+type MergeBy int
+
+const (
+	ByObserved MergeBy = iota
+	ByExpected
+)
+
+// adjacent bins are merged while a bin holds less than Minimum, 0 disables merging
+type Merging struct {
+	By      MergeBy
+	Minimum float64
+}
+
+// lb5's original rule, observed counts below 5
+func DefaultMerging() Merging {
+	return Merging{By: ByObserved, Minimum: DefaultMinimum}
+}
+
+// the textbook rule, expected counts below minimum
+func ExpectedMerging(minimum float64) Merging {
+	return Merging{By: ByExpected, Minimum: minimum}
+}
+
+// bin over the integer values Lower..Upper (inclusive)
+type Bin struct {
+	Lower int
+	Upper int
+	Count int
+}
+
+type Histogram struct {
+	Bins       []Bin
+	TotalCount int
+}
+
+// one cell of a goodness-of-fit test: the interval [Lower, Upper) (bounds may be infinite, discrete
+// categories use their labels) with what was observed there and what H0 expects
+type Category struct {
+	Lower    float64
+	Upper    float64
+	Observed int
+	Expected float64
+}
+
+// merged category Lower..Upper with the observed count of every sample and the counts expected under H0
+type Group struct {
+	Lower    float64
+	Upper    float64
+	Observed []int
+	Expected []float64
+}
+
+// categories First..Last (indices before merging, covering Lower..Upper) were combined because
+// their running weight stayed below the minimum. Weight is what the merged group ended up with
+type MergeDecision struct {
+	First  int
+	Last   int
+	Lower  float64
+	Upper  float64
+	Weight float64
+}
+
+type Result struct {
+	Statistic float64
+	// groups − 1 − Estimated for one sample, (samples − 1)(groups − 1) for homogeneity
+	DF        int
+	Estimated int
+	PValue    float64
+	Groups    []Group
+	Merges    []MergeDecision
+}
+
+// one bin per value present in min..max. only observed counts exist at this point,
+// so merging always looks at them whatever merging.By says
+func CreateHistogram(sample []int, min, max int, merging Merging) *Histogram {
+	freq := make(map[int]int)
+	for _, val := range sample {
+		freq[val]++
+	}
+
+	bins := make([]Bin, 0)
+	for i := min; i <= max; i++ {
+		if freq[i] > 0 {
+			bins = append(bins, Bin{
+				Lower: i,
+				Upper: i,
+				Count: freq[i],
+			})
+		}
+	}
+
+	return &Histogram{
+		Bins:       MergeBins(bins, merging),
+		TotalCount: len(sample),
+	}
+}
+
+func MergeBins(bins []Bin, merging Merging) []Bin {
+	var counts = make([]float64, len(bins))
+	for i, bin := range bins {
+		counts[i] = float64(bin.Count)
+	}
+
+	var merged = make([]Bin, 0)
+	for _, span := range mergeSpans(counts, merging.Minimum) {
+		var bin = Bin{Lower: bins[span[0]].Lower, Upper: bins[span[1]].Upper}
+		for i := span[0]; i <= span[1]; i++ {
+			bin.Count += bins[i].Count
+		}
+		merged = append(merged, bin)
+	}
+
+	return merged
+}
+
+// pearson chi-squared test over adjacent categories. estimated is the number of distribution
+// parameters fitted from the same data, each costs one degree of freedom
+func Test(categories []Category, estimated int, merging Merging) (Result, error) {
+	var weights = make([]float64, len(categories))
+	for i, c := range categories {
+		weights[i] = float64(c.Observed)
+		if merging.By == ByExpected {
+			weights[i] = c.Expected
+		}
+	}
+
+	var spans = mergeSpans(weights, merging.Minimum)
+	var result = Result{Estimated: estimated, Merges: decisions(spans, weights, func(i int) (float64, float64) {
+		return categories[i].Lower, categories[i].Upper
+	})}
+
+	for _, span := range spans {
+		var group = Group{
+			Lower:    categories[span[0]].Lower,
+			Upper:    categories[span[1]].Upper,
+			Observed: []int{0},
+			Expected: []float64{0},
+		}
+		for i := span[0]; i <= span[1]; i++ {
+			group.Observed[0] += categories[i].Observed
+			group.Expected[0] += categories[i].Expected
+		}
+		result.Groups = append(result.Groups, group)
+
+		if group.Expected[0] > 0 {
+			var diff = float64(group.Observed[0]) - group.Expected[0]
+			result.Statistic += diff * diff / group.Expected[0]
+		} else if group.Observed[0] > 0 {
+			result.Statistic = math.Inf(1)
+		}
+	}
+
+	result.DF = len(result.Groups) - 1 - estimated
+	if result.DF <= 0 {
+		return result, errors.New("goodness of fit: not enough bins left after merging (degrees of freedom <= 0)")
+	}
+	result.PValue = distribution.ChiSquared{K: float64(result.DF)}.Survival(result.Statistic)
+
+	return result, nil
+}
+
+// chi-squared test of the observed table against fixed category probabilities (e.g. a loaded die).
+// probabilities follow the table's variants and must sum to one
+func Multinomial(observed interfaces.IDistribution, probabilities []float64, merging Merging) (Result, error) {
+	var occurences = observed.GetOccurences()
+	if len(occurences) != len(probabilities) {
+		return Result{}, fmt.Errorf("multinomial: %d categories but %d probabilities", len(occurences), len(probabilities))
+	}
+
+	var total, sum = 0, 0.0
+	for i, o := range occurences {
+		total += o
+		sum += probabilities[i]
+		if probabilities[i] < 0 {
+			return Result{}, errors.New("multinomial: probabilities must be non-negative")
+		}
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		return Result{}, fmt.Errorf("multinomial: probabilities sum to %g", sum)
+	}
+
+	// categories are numbered from 1 like StatisticalDistribution variants
+	var categories = make([]Category, len(occurences))
+	for i, o := range occurences {
+		categories[i] = Category{Lower: float64(i + 1), Upper: float64(i + 1), Observed: o, Expected: probabilities[i] * float64(total)}
+	}
+
+	return Test(categories, 0, merging)
+}
+
+// chi-squared test of a continuous fit. edges are the ascending inner bin boundaries, the outer bins
+// extend to ±infinity so the tails of the fitted distribution are part of the test.
+// bins are [edge, next edge), estimated is the number of parameters fitted from values
+func ContinuousFit(values interfaces.ISample, edges []float64, cdf func(float64) float64, estimated int, merging Merging) (Result, error) {
+	var bounds = append(append([]float64{math.Inf(-1)}, edges...), math.Inf(1))
+	var categories = make([]Category, len(bounds)-1)
+	var data = values.GetValues()
+
+	for i := range categories {
+		categories[i] = Category{
+			Lower:    bounds[i],
+			Upper:    bounds[i+1],
+			Expected: float64(len(data)) * (cdf(bounds[i+1]) - cdf(bounds[i])),
+		}
+	}
+
+	for _, v := range data {
+		var idx = sort.SearchFloat64s(edges, v)
+		if idx < len(edges) && edges[idx] == v {
+			idx++
+		}
+		categories[idx].Observed++
+	}
+
+	return Test(categories, estimated, merging)
+}
+
+// inner edges halfway between the integers low..high, for integer data fitted by a continuous distribution:
+// (−∞, low+0.5), [low+0.5, low+1.5), ..., [high−0.5, +∞)
+func IntegerEdges(low, high int) []float64 {
+	var edges = []float64{}
+	for v := low; v < high; v++ {
+		edges = append(edges, float64(v)+0.5)
+	}
+	return edges
+}
+
+// chi-squared test that several samples come from the same discrete distribution.
+// categories line up by position in every table and are merged on the pooled counts,
+// ByExpected uses the smallest expected count of the column
+func Homogeneity(merging Merging, tables ...interfaces.IDistribution) (Result, error) {
+	if len(tables) < 2 {
+		return Result{}, errors.New("homogeneity: at least two tables are needed")
+	}
+
+	var width = 0
+	var totals = make([]float64, len(tables))
+	var grand = 0.0
+	for i, t := range tables {
+		width = max(width, len(t.GetOccurences()))
+		for _, o := range t.GetOccurences() {
+			totals[i] += float64(o)
+		}
+		grand += totals[i]
+	}
+
+	var pooled = make([]float64, width)
+	for _, t := range tables {
+		for j, o := range t.GetOccurences() {
+			pooled[j] += float64(o)
+		}
+	}
+
+	// the smallest sample gets the smallest expected count in every column
+	var smallest = totals[0]
+	for _, total := range totals {
+		smallest = math.Min(smallest, total)
+	}
+
+	// drop categories nobody observed, they carry no information
+	var present = []int{}
+	var weights = []float64{}
+	for j, c := range pooled {
+		if c > 0 {
+			present = append(present, j)
+			if merging.By == ByExpected {
+				weights = append(weights, c*smallest/grand)
+			} else {
+				weights = append(weights, c)
+			}
+		}
+	}
+
+	var spans = mergeSpans(weights, merging.Minimum)
+	var counts = make([][]int, len(tables))
+	var result = Result{Groups: make([]Group, len(spans)), Merges: decisions(spans, weights, func(i int) (float64, float64) {
+		return float64(present[i] + 1), float64(present[i] + 1)
+	})}
+
+	for s, span := range spans {
+		result.Groups[s] = Group{
+			Lower:    float64(present[span[0]] + 1),
+			Upper:    float64(present[span[1]] + 1),
+			Observed: make([]int, len(tables)),
+		}
+	}
+
+	for i, t := range tables {
+		var occurences = t.GetOccurences()
+		counts[i] = make([]int, len(spans))
+		for s, span := range spans {
+			for p := span[0]; p <= span[1]; p++ {
+				if present[p] < len(occurences) {
+					counts[i][s] += occurences[present[p]]
+				}
+			}
+			result.Groups[s].Observed[i] = counts[i][s]
+		}
+	}
+
+	table, err := contingency.FromCounts(counts)
+	if err != nil {
+		return result, err
+	}
+	var expected = table.Expected()
+	for s := range result.Groups {
+		result.Groups[s].Expected = make([]float64, len(tables))
+		for i := range tables {
+			result.Groups[s].Expected[i] = expected[i][s]
+		}
+	}
+
+	result.DF = int(table.DF())
+	if result.DF <= 0 {
+		return result, errors.New("homogeneity: not enough categories left after merging (degrees of freedom <= 0)")
+	}
+
+	var test = table.ChiSquare(false)
+	result.Statistic, result.PValue = test.Statistic, test.PValue
+
+	return result, nil
+}
+
+// lb5's merging walk: grow the current span while its weight is below minimum,
+// a light remainder at the end is folded into the previous span
+func mergeSpans(weights []float64, minimum float64) [][2]int {
+	if len(weights) == 0 {
+		return [][2]int{}
+	}
+
+	var spans = [][2]int{}
+	var current = [2]int{0, 0}
+	var weight = weights[0]
+
+	for i := 1; i < len(weights); i++ {
+		if weight < minimum {
+			current[1] = i
+			weight += weights[i]
+		} else {
+			spans = append(spans, current)
+			current = [2]int{i, i}
+			weight = weights[i]
+		}
+	}
+
+	if len(spans) > 0 && weight < minimum {
+		spans[len(spans)-1][1] = current[1]
+	} else {
+		spans = append(spans, current)
+	}
+
+	return spans
+}
+
+func decisions(spans [][2]int, weights []float64, bounds func(i int) (float64, float64)) []MergeDecision {
+	var merges = []MergeDecision{}
+	for _, span := range spans {
+		if span[0] == span[1] {
+			continue
+		}
+
+		var weight = 0.0
+		for i := span[0]; i <= span[1]; i++ {
+			weight += weights[i]
+		}
+		var lower, _ = bounds(span[0])
+		var _, upper = bounds(span[1])
+		merges = append(merges, MergeDecision{First: span[0], Last: span[1], Lower: lower, Upper: upper, Weight: weight})
+	}
+	return merges
+}
@@ -0,0 +1,177 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"shared/interfaces"
+)
+
+// critical values of the kolmogorov–smirnov D when the parameters are estimated from the sample.
+// Critical[i][j] belongs to Sizes[i] and Levels[j], above the last size it is Asymptotic[j] / √n
+type LillieforsTable struct {
+	Family     string
+	Levels     []float64
+	Sizes      []int
+	Critical   [][]float64
+	Asymptotic []float64
+	Fit        Fitter
+}
+
+type LillieforsResult struct {
+	Statistic float64
+	N         int
+	Levels    []float64
+	Critical  []float64
+	// the tables only bracket the p-value: PValueLower < p ≤ PValueUpper
+	PValueLower float64
+	PValueUpper float64
+	Fit         Fitted
+}
+
+// lilliefors (1967), normal with estimated mean and standard deviation
+var LillieforsNormalTable = LillieforsTable{
+	Family: "normal",
+	Levels: []float64{0.20, 0.15, 0.10, 0.05, 0.01},
+	Sizes:  []int{4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 25, 30},
+	Critical: [][]float64{
+		{0.300, 0.319, 0.352, 0.381, 0.417},
+		{0.285, 0.299, 0.315, 0.337, 0.405},
+		{0.265, 0.277, 0.294, 0.319, 0.364},
+		{0.247, 0.258, 0.276, 0.300, 0.348},
+		{0.233, 0.244, 0.261, 0.285, 0.331},
+		{0.223, 0.233, 0.249, 0.271, 0.311},
+		{0.215, 0.224, 0.239, 0.258, 0.294},
+		{0.206, 0.217, 0.230, 0.249, 0.284},
+		{0.199, 0.212, 0.223, 0.242, 0.275},
+		{0.190, 0.202, 0.214, 0.234, 0.268},
+		{0.183, 0.194, 0.207, 0.227, 0.261},
+		{0.177, 0.187, 0.201, 0.220, 0.257},
+		{0.173, 0.182, 0.195, 0.213, 0.250},
+		{0.169, 0.177, 0.189, 0.206, 0.245},
+		{0.166, 0.173, 0.184, 0.200, 0.239},
+		{0.163, 0.169, 0.179, 0.195, 0.235},
+		{0.160, 0.166, 0.174, 0.190, 0.231},
+		{0.142, 0.147, 0.158, 0.173, 0.200},
+		{0.131, 0.136, 0.144, 0.161, 0.187},
+	},
+	Asymptotic: []float64{0.736, 0.768, 0.805, 0.886, 1.031},
+	Fit:        FitNormal,
+}
+
+// lilliefors (1969), exponential with estimated mean
+var LillieforsExponentialTable = LillieforsTable{
+	Family: "exponential",
+	Levels: []float64{0.20, 0.15, 0.10, 0.05, 0.01},
+	Sizes:  []int{3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 25, 30},
+	Critical: [][]float64{
+		{0.451, 0.479, 0.511, 0.551, 0.600},
+		{0.396, 0.422, 0.449, 0.487, 0.548},
+		{0.359, 0.382, 0.406, 0.442, 0.504},
+		{0.331, 0.351, 0.375, 0.408, 0.470},
+		{0.309, 0.327, 0.350, 0.382, 0.442},
+		{0.291, 0.308, 0.329, 0.360, 0.419},
+		{0.277, 0.291, 0.311, 0.341, 0.399},
+		{0.263, 0.277, 0.295, 0.325, 0.380},
+		{0.251, 0.264, 0.283, 0.311, 0.365},
+		{0.241, 0.254, 0.271, 0.298, 0.351},
+		{0.232, 0.245, 0.261, 0.287, 0.338},
+		{0.224, 0.237, 0.252, 0.277, 0.326},
+		{0.217, 0.229, 0.244, 0.269, 0.315},
+		{0.211, 0.222, 0.236, 0.261, 0.306},
+		{0.204, 0.215, 0.229, 0.253, 0.297},
+		{0.199, 0.210, 0.223, 0.246, 0.289},
+		{0.193, 0.204, 0.218, 0.239, 0.283},
+		{0.188, 0.199, 0.212, 0.234, 0.278},
+		{0.170, 0.180, 0.191, 0.210, 0.247},
+		{0.155, 0.164, 0.174, 0.192, 0.226},
+	},
+	Asymptotic: []float64{0.86, 0.91, 0.96, 1.06, 1.25},
+	Fit:        FitExponential,
+}
+
+// critical values of every level for sample size n, linear in n between tabulated sizes
+func (t LillieforsTable) CriticalValues(n int) ([]float64, error) {
+	var first, last = t.Sizes[0], t.Sizes[len(t.Sizes)-1]
+	if n < first {
+		return nil, fmt.Errorf("lilliefors: the %s table starts at n = %d", t.Family, first)
+	}
+
+	var critical = make([]float64, len(t.Levels))
+	if n > last {
+		for j, c := range t.Asymptotic {
+			critical[j] = c / math.Sqrt(float64(n))
+		}
+		return critical, nil
+	}
+
+	if n == first {
+		copy(critical, t.Critical[0])
+		return critical, nil
+	}
+
+	var i = 0
+	for t.Sizes[i+1] < n {
+		i++
+	}
+
+	var w = float64(n-t.Sizes[i]) / float64(t.Sizes[i+1]-t.Sizes[i])
+	for j := range critical {
+		critical[j] = (1-w)*t.Critical[i][j] + w*t.Critical[i+1][j]
+	}
+
+	return critical, nil
+}
+
+// critical value of one tabulated level
+func (t LillieforsTable) CriticalValue(n int, alpha float64) (float64, error) {
+	critical, err := t.CriticalValues(n)
+	if err != nil {
+		return 0, err
+	}
+
+	for j, level := range t.Levels {
+		if math.Abs(level-alpha) < 1e-12 {
+			return critical[j], nil
+		}
+	}
+
+	return 0, fmt.Errorf("lilliefors: α = %g is not tabulated, use ParametricBootstrap", alpha)
+}
+
+// kolmogorov–smirnov test of the table's family with parameters fitted from values
+func (t LillieforsTable) Test(values interfaces.ISample) (LillieforsResult, error) {
+	var data = values.GetValues()
+	critical, err := t.CriticalValues(len(data))
+	if err != nil {
+		return LillieforsResult{}, err
+	}
+
+	fitted, err := t.Fit(data)
+	if err != nil {
+		return LillieforsResult{}, err
+	}
+
+	var result = LillieforsResult{
+		Statistic:   KolmogorovSmirnov(data, fitted),
+		N:           len(data),
+		Levels:      t.Levels,
+		Critical:    critical,
+		PValueLower: t.Levels[0],
+		PValueUpper: 1,
+		Fit:         fitted,
+	}
+
+	// levels are descending and critical values ascending, stop at the first one D exceeds
+	for j := range t.Levels {
+		if result.Statistic <= critical[j] {
+			break
+		}
+		result.PValueUpper = t.Levels[j]
+		result.PValueLower = 0
+		if j+1 < len(t.Levels) {
+			result.PValueLower = t.Levels[j+1]
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,262 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"shared/interfaces"
+)
+
+// class [Lower, Upper) of grouped data and how many observations fell in it
+type Class struct {
+	Lower     float64
+	Upper     float64
+	Frequency int
+}
+
+func (c Class) Midpoint() float64 {
+	return (c.Lower + c.Upper) / 2
+}
+
+func (c Class) Width() float64 {
+	return c.Upper - c.Lower
+}
+
+// classes in ascending order, the individual observations are only known up to their class
+type Table []Class
+
+// central moments of grouped data, every observation taken at its class midpoint and divided by n
+type Moments struct {
+	N        int
+	Mean     float64
+	Variance float64
+	Third    float64
+	Fourth   float64
+	// Variance and Fourth have sheppard's correction applied
+	Sheppard bool
+}
+
+// variant x becomes the class [x − 1/2, x + 1/2), variants that never occurred are left out
+func FromDistribution(d interfaces.IDistribution) Table {
+	var variants, occurences = d.GetVariants(), d.GetOccurences()
+
+	var table = Table{}
+	for i, x := range variants {
+		if occurences[i] == 0 {
+			continue
+		}
+		table = append(table, Class{Lower: float64(x) - 0.5, Upper: float64(x) + 0.5, Frequency: occurences[i]})
+	}
+
+	return table
+}
+
+// classes between consecutive edges, frequencies[i] counts [edges[i], edges[i+1])
+func FromEdges(edges []float64, frequencies []int) (Table, error) {
+	if len(edges) != len(frequencies)+1 {
+		return nil, fmt.Errorf("grouped: %d edges cannot bound %d classes", len(edges), len(frequencies))
+	}
+
+	var table = make(Table, len(frequencies))
+	for i, f := range frequencies {
+		if edges[i+1] <= edges[i] {
+			return nil, fmt.Errorf("grouped: edges must increase, got %g after %g", edges[i+1], edges[i])
+		}
+		if f < 0 {
+			return nil, fmt.Errorf("grouped: negative frequency %d", f)
+		}
+		table[i] = Class{Lower: edges[i], Upper: edges[i+1], Frequency: f}
+	}
+
+	return table, nil
+}
+
+func (t Table) N() int {
+	var n = 0
+	for _, c := range t {
+		n += c.Frequency
+	}
+	return n
+}
+
+func (t Table) Mean() float64 {
+	var sum = 0.0
+	for _, c := range t {
+		sum += float64(c.Frequency) * c.Midpoint()
+	}
+	return sum / float64(t.N())
+}
+
+// Σ f midpoint^k / n
+func (t Table) RawMoment(k int) float64 {
+	var sum = 0.0
+	for _, c := range t {
+		sum += float64(c.Frequency) * math.Pow(c.Midpoint(), float64(k))
+	}
+	return sum / float64(t.N())
+}
+
+// Σ f (midpoint − mean)^k / n
+func (t Table) CentralMoment(k int) float64 {
+	var mean = t.Mean()
+	var sum = 0.0
+	for _, c := range t {
+		sum += float64(c.Frequency) * math.Pow(c.Midpoint()-mean, float64(k))
+	}
+	return sum / float64(t.N())
+}
+
+// the common width of the classes, an error when they differ
+func (t Table) Width() (float64, error) {
+	if len(t) == 0 {
+		return 0, errors.New("grouped: no classes")
+	}
+
+	var h = t[0].Width()
+	for _, c := range t[1:] {
+		if math.Abs(c.Width()-h) > 1e-9*math.Max(1, h) {
+			return 0, fmt.Errorf("grouped: classes have different widths (%g and %g)", h, c.Width())
+		}
+	}
+
+	return h, nil
+}
+
+// mean and central moments up to the fourth. sheppard's correction removes the spread the midpoints add
+// to a smooth density: μ2 − h²/12 and μ4 − h²μ2/2 + 7h⁴/240 for classes of width h. it needs equal widths
+// and a density that falls off to zero at both ends, not j-shaped data like the exponential
+func (t Table) Moments(sheppard bool) (Moments, error) {
+	var n = t.N()
+	if n == 0 {
+		return Moments{}, errors.New("grouped: the table is empty")
+	}
+
+	var moments = Moments{
+		N:        n,
+		Mean:     t.Mean(),
+		Variance: t.CentralMoment(2),
+		Third:    t.CentralMoment(3),
+		Fourth:   t.CentralMoment(4),
+		Sheppard: sheppard,
+	}
+
+	if sheppard {
+		h, err := t.Width()
+		if err != nil {
+			return Moments{}, err
+		}
+
+		var h2 = h * h
+		moments.Fourth = moments.Fourth - h2*moments.Variance/2 + 7*h2*h2/240
+		moments.Variance -= h2 / 12
+		if moments.Variance <= 0 {
+			return Moments{}, errors.New("grouped: the classes are too wide for sheppard's correction")
+		}
+	}
+
+	return moments, nil
+}
+
+// Σ f (midpoint − mean)² / n
+func (t Table) Variance() float64 {
+	return t.CentralMoment(2)
+}
+
+// Variance with n − 1, the unbiased estimate of the population variance
+func (t Table) SampleVariance() float64 {
+	var n = float64(t.N())
+	return t.Variance() * n / (n - 1)
+}
+
+func (t Table) StdDev() float64 {
+	return math.Sqrt(t.Variance())
+}
+
+// μ3 / μ2^(3/2)
+func (t Table) Skewness() float64 {
+	return t.CentralMoment(3) / math.Pow(t.CentralMoment(2), 1.5)
+}
+
+// excess kurtosis μ4 / μ2² − 3, zero for the normal
+func (t Table) Kurtosis() float64 {
+	var m2 = t.CentralMoment(2)
+	return t.CentralMoment(4)/(m2*m2) - 3
+}
+
+// Qp = x₀ + h (p·n − S) / f, with x₀, h and f the lower bound, width and frequency of the class holding
+// the p·n-th observation and S the frequency of the classes before it. observations spread evenly over a class
+func (t Table) Quantile(p float64) (float64, error) {
+	if p < 0 || p > 1 || math.IsNaN(p) {
+		return 0, fmt.Errorf("grouped: quantile level %g is outside [0, 1]", p)
+	}
+
+	var n = t.N()
+	if n == 0 {
+		return 0, errors.New("grouped: the table is empty")
+	}
+
+	var target = p * float64(n)
+	var before = 0
+	for _, c := range t {
+		if c.Frequency == 0 {
+			continue
+		}
+		if float64(before+c.Frequency) >= target {
+			return c.Lower + c.Width()*(target-float64(before))/float64(c.Frequency), nil
+		}
+		before += c.Frequency
+	}
+
+	return t[len(t)-1].Upper, nil
+}
+
+// Me = x₀ + h (n/2 − S) / f
+func (t Table) Median() (float64, error) {
+	return t.Quantile(0.5)
+}
+
+// first, second and third quartile
+func (t Table) Quartiles() ([3]float64, error) {
+	var quartiles [3]float64
+	for i := range quartiles {
+		q, err := t.Quantile(float64(i+1) / 4)
+		if err != nil {
+			return quartiles, err
+		}
+		quartiles[i] = q
+	}
+	return quartiles, nil
+}
+
+// Mo = x₀ + h (fₘ − fₘ₋₁) / ((fₘ − fₘ₋₁) + (fₘ − fₘ₊₁)) in the modal class m, with no neighbour counting as
+// zero. classes of different widths are compared by frequency per unit width. of several modal classes the
+// first one is taken, two adjacent ones share the mode at their common bound
+func (t Table) Mode() (float64, error) {
+	if t.N() == 0 {
+		return 0, errors.New("grouped: the table is empty")
+	}
+
+	var densities = make([]float64, len(t))
+	var m = 0
+	for i, c := range t {
+		densities[i] = float64(c.Frequency) / c.Width()
+		if densities[i] > densities[m] {
+			m = i
+		}
+	}
+
+	var previous, next = 0.0, 0.0
+	if m > 0 {
+		previous = densities[m-1]
+	}
+	if m < len(t)-1 {
+		next = densities[m+1]
+	}
+
+	var rise, fall = densities[m] - previous, densities[m] - next
+	if rise+fall == 0 {
+		return t[m].Midpoint(), nil
+	}
+
+	return t[m].Lower + t[m].Width()*rise/(rise+fall), nil
+}
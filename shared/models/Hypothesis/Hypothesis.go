@@ -0,0 +1,45 @@
+package shared
+
+import "fmt"
+
+// direction of H1 relative to H0
+type Alternative int
+
+const (
+	TwoSided Alternative = iota
+	Less
+	Greater
+)
+
+func (a Alternative) String() string {
+	switch a {
+	case TwoSided:
+		return "two-sided"
+	case Less:
+		return "less"
+	case Greater:
+		return "greater"
+	default:
+		return fmt.Sprintf("Alternative(%d)", int(a))
+	}
+}
+
+// p-value of a statistic whose null distribution has the given cdf (continuous and symmetric when two-sided)
+func PValue(cdf func(float64) float64, statistic float64, alternative Alternative) float64 {
+	switch alternative {
+	case Less:
+		return cdf(statistic)
+	case Greater:
+		return 1 - cdf(statistic)
+	default:
+		var tail = cdf(-abs(statistic))
+		return min(1, 2*tail)
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,395 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	distribution "shared/models/Distribution"
+	sample "shared/models/Sample"
+	simulation "shared/models/Simulation"
+)
+
+type Family int
+
+const (
+	Gaussian Family = iota
+	// for counts, every component's variance equals its mean
+	Poisson
+)
+
+func (f Family) String() string {
+	if f == Poisson {
+		return "poisson"
+	}
+	return "gaussian"
+}
+
+type Component struct {
+	Weight   float64
+	Mean     float64
+	Variance float64
+}
+
+type Fit struct {
+	Family Family
+	// ascending by mean
+	Components []Component
+	// Responsibilities[i][j] is the probability that value i came from component j
+	Responsibilities [][]float64
+	N                int
+	LogLikelihood    float64
+	AIC              float64
+	BIC              float64
+	Iterations       int
+	Converged        bool
+	// restarts that ran, the best one is kept
+	Restarts int
+}
+
+// fits for k = 1, 2, ..., Best indexes the one with the lowest BIC
+type Selection struct {
+	Fits []Fit
+	Best int
+}
+
+const (
+	MaxIterations = 1000
+	// relative change of the log-likelihood that ends the iterations
+	Tolerance = 1e-10
+	// gaussian variances stay above this share of the sample variance, otherwise a component can shrink
+	// onto one repeated value and the likelihood grows without bound
+	VarianceFloor = 1e-3
+)
+
+// em fit of k components of the family. every restart (config.Replications of them, restart i on stream i of
+// config.Seed) starts from k-means++ seeds refined by k-means, the one with the highest likelihood wins
+func Estimate(values interfaces.ISample, family Family, k int, config simulation.Config) (Fit, error) {
+	var data = values.GetValues()
+	if k < 1 {
+		return Fit{}, errors.New("mixture: at least one component is needed")
+	}
+	if distinct(data) < k {
+		return Fit{}, fmt.Errorf("mixture: %d components need at least as many distinct values", k)
+	}
+	if family == Poisson {
+		for _, v := range data {
+			if v < 0 || v != math.Trunc(v) {
+				return Fit{}, fmt.Errorf("mixture: %g is not a count", v)
+			}
+		}
+	}
+
+	var floor = VarianceFloor * sample.Variance(data)
+	// NaN for a single value
+	if family == Gaussian && !(floor > 0) {
+		return Fit{}, errors.New("mixture: every value is the same, gaussian components need a positive sample variance")
+	}
+	runs, err := simulation.Replicate(config, func(rnd *rand.Rand) Fit {
+		return em(data, family, initialize(rnd, data, k), floor)
+	})
+	if err != nil {
+		return Fit{}, err
+	}
+	if len(runs) == 0 {
+		return Fit{}, errors.New("mixture: no restarts configured")
+	}
+
+	var best = runs[0]
+	for _, run := range runs[1:] {
+		if run.LogLikelihood > best.LogLikelihood {
+			best = run
+		}
+	}
+	best.Restarts = len(runs)
+
+	return best, nil
+}
+
+// Estimate for k = 1..maxK, stopping early when the data has fewer distinct values
+func Select(values interfaces.ISample, family Family, maxK int, config simulation.Config) (Selection, error) {
+	var selection = Selection{}
+	for k := 1; k <= maxK; k++ {
+		fit, err := Estimate(values, family, k, config)
+		if err != nil {
+			if k == 1 {
+				return selection, err
+			}
+			break
+		}
+
+		selection.Fits = append(selection.Fits, fit)
+		if fit.BIC < selection.Fits[selection.Best].BIC {
+			selection.Best = len(selection.Fits) - 1
+		}
+	}
+
+	return selection, nil
+}
+
+func (s Selection) Chosen() Fit {
+	return s.Fits[s.Best]
+}
+
+// the most likely component of every value
+func (f Fit) Classify() []int {
+	var labels = make([]int, len(f.Responsibilities))
+	for i, r := range f.Responsibilities {
+		for j := range r {
+			if r[j] > r[labels[i]] {
+				labels[i] = j
+			}
+		}
+	}
+	return labels
+}
+
+func (f Fit) PDF(x float64) float64 {
+	var sum = 0.0
+	for _, c := range f.Components {
+		sum += c.Weight * density(f.Family, c, x)
+	}
+	return sum
+}
+
+func (f Fit) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "%d %s components, log L = %.4f, BIC = %.4f (%d iterations, %d restarts)\n",
+		len(f.Components), f.Family, f.LogLikelihood, f.BIC, f.Iterations, f.Restarts)
+
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Component\t Weight\t Mean\t Variance\t")
+	for j, c := range f.Components {
+		fmt.Fprintf(writer, " %d\t %.4f\t %.4f\t %.4f\t\n", j+1, c.Weight, c.Mean, c.Variance)
+	}
+
+	return writer.Flush()
+}
+
+func (s Selection) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " k\t log L\t AIC\t BIC\t ΔBIC\t")
+	for i, f := range s.Fits {
+		var mark = ""
+		if i == s.Best {
+			mark = " (chosen)"
+		}
+		fmt.Fprintf(writer, " %d%s\t %.4f\t %.4f\t %.4f\t %.4f\t\n", len(f.Components), mark, f.LogLikelihood, f.AIC, f.BIC, f.BIC-s.Chosen().BIC)
+	}
+
+	return writer.Flush()
+}
+
+// expectation–maximisation from the starting components until the log-likelihood settles
+func em(data []float64, family Family, components []Component, floor float64) Fit {
+	var n, k = len(data), len(components)
+	var responsibilities = make([][]float64, n)
+	for i := range responsibilities {
+		responsibilities[i] = make([]float64, k)
+	}
+
+	var fit = Fit{Family: family, N: n, LogLikelihood: math.Inf(-1)}
+	for fit.Iterations < MaxIterations {
+		fit.Iterations++
+
+		// e step on the log scale, a far value must not underflow every component
+		var logLikelihood = 0.0
+		for i, x := range data {
+			var logs = make([]float64, k)
+			var largest = math.Inf(-1)
+			for j, c := range components {
+				logs[j] = math.Log(c.Weight) + logDensity(family, c, x)
+				largest = math.Max(largest, logs[j])
+			}
+
+			var sum = 0.0
+			for j := range logs {
+				responsibilities[i][j] = math.Exp(logs[j] - largest)
+				sum += responsibilities[i][j]
+			}
+			for j := range logs {
+				responsibilities[i][j] /= sum
+			}
+			logLikelihood += largest + math.Log(sum)
+		}
+
+		var change = logLikelihood - fit.LogLikelihood
+		fit.LogLikelihood = logLikelihood
+		if change <= Tolerance*math.Max(1, math.Abs(logLikelihood)) {
+			fit.Converged = true
+			break
+		}
+
+		// m step
+		for j := range components {
+			var weight, mean = 0.0, 0.0
+			for i, x := range data {
+				weight += responsibilities[i][j]
+				mean += responsibilities[i][j] * x
+			}
+			// every value has left the component, it keeps its place with no weight so the others still sum to 1
+			if weight == 0 {
+				components[j].Weight = 0
+				continue
+			}
+			mean /= weight
+
+			var variance = mean
+			if family == Gaussian {
+				variance = 0
+				for i, x := range data {
+					variance += responsibilities[i][j] * (x - mean) * (x - mean)
+				}
+				variance = math.Max(variance/weight, floor)
+			}
+
+			components[j] = Component{Weight: weight / float64(n), Mean: mean, Variance: variance}
+		}
+	}
+
+	// ascending means, the responsibilities follow
+	var order = make([]int, k)
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool { return components[order[a]].Mean < components[order[b]].Mean })
+
+	for _, j := range order {
+		fit.Components = append(fit.Components, components[j])
+	}
+	fit.Responsibilities = make([][]float64, n)
+	for i := range responsibilities {
+		fit.Responsibilities[i] = make([]float64, k)
+		for to, from := range order {
+			fit.Responsibilities[i][to] = responsibilities[i][from]
+		}
+	}
+
+	var parameters = float64(2*k - 1)
+	if family == Gaussian {
+		parameters = float64(3*k - 1)
+	}
+	fit.AIC = 2*parameters - 2*fit.LogLikelihood
+	fit.BIC = parameters*math.Log(float64(n)) - 2*fit.LogLikelihood
+
+	return fit
+}
+
+// k-means++ seeds (each next centre drawn with probability proportional to its squared distance from the
+// nearest chosen one) refined by lloyd's iterations, then the clusters' shares, means and variances
+func initialize(rnd *rand.Rand, data []float64, k int) []Component {
+	var centres = []float64{data[rnd.Intn(len(data))]}
+	var distances = make([]float64, len(data))
+	for len(centres) < k {
+		var total = 0.0
+		for i, x := range data {
+			distances[i] = math.Inf(1)
+			for _, c := range centres {
+				distances[i] = math.Min(distances[i], (x-c)*(x-c))
+			}
+			total += distances[i]
+		}
+
+		var target = rnd.Float64() * total
+		var next = len(data) - 1
+		for i, d := range distances {
+			if target < d {
+				next = i
+				break
+			}
+			target -= d
+		}
+		centres = append(centres, data[next])
+	}
+
+	var labels = make([]int, len(data))
+	for iteration := 0; iteration < 100; iteration++ {
+		var changed = iteration == 0
+		for i, x := range data {
+			var nearest = 0
+			for j, c := range centres {
+				if math.Abs(x-c) < math.Abs(x-centres[nearest]) {
+					nearest = j
+				}
+			}
+			if nearest != labels[i] {
+				labels[i] = nearest
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		var sums, counts = make([]float64, k), make([]float64, k)
+		for i, x := range data {
+			sums[labels[i]] += x
+			counts[labels[i]]++
+		}
+		for j := range centres {
+			if counts[j] > 0 {
+				centres[j] = sums[j] / counts[j]
+			}
+		}
+	}
+
+	var spread = sample.Variance(data)
+	var components = make([]Component, k)
+	for j := range components {
+		var members = []float64{}
+		for i, x := range data {
+			if labels[i] == j {
+				members = append(members, x)
+			}
+		}
+
+		var variance = spread
+		if len(members) > 1 && sample.Variance(members) > 0 {
+			variance = sample.Variance(members)
+		}
+		components[j] = Component{
+			Weight:   math.Max(float64(len(members)), 1) / float64(len(data)),
+			Mean:     centres[j],
+			Variance: variance,
+		}
+	}
+
+	return components
+}
+
+func logDensity(family Family, c Component, x float64) float64 {
+	if family == Poisson {
+		var lambda = math.Max(c.Mean, 1e-12)
+		lg, _ := math.Lgamma(x + 1)
+		return x*math.Log(lambda) - lambda - lg
+	}
+	return -0.5*math.Log(2*math.Pi*c.Variance) - (x-c.Mean)*(x-c.Mean)/(2*c.Variance)
+}
+
+func density(family Family, c Component, x float64) float64 {
+	if family == Poisson {
+		if x != math.Trunc(x) {
+			return 0
+		}
+		return distribution.Poisson{Lambda: c.Mean}.PMF(int(x))
+	}
+	return distribution.Normal{Mu: c.Mean, Sigma: math.Sqrt(c.Variance)}.PDF(x)
+}
+
+func distinct(values []float64) int {
+	var seen = map[float64]bool{}
+	for _, v := range values {
+		seen[v] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,230 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	density "shared/models/Density"
+	grouped "shared/models/Grouped"
+)
+
+// a local maximum of a frequency or density curve. every value in [From, To] reaches Height,
+// a strict peak has From == To
+type Peak struct {
+	Location float64
+	From     float64
+	To       float64
+	Height   float64
+	// how far the curve has to descend from the peak before it can climb to a higher one,
+	// the highest peak descends to the curve's lowest point. small prominences are noise
+	Prominence float64
+	// 1 for the most prominent peak
+	Rank int
+}
+
+type Analysis struct {
+	// what the curve was estimated from
+	Source string
+	// ranked, most prominent first
+	Peaks []Peak
+	// the curve the peaks were found on
+	Grid    []float64
+	Heights []float64
+}
+
+// grid points of the kernel density estimate FromSample searches
+const GridPoints = 512
+
+// peaks of the curve heights over the ascending grid. beyond both ends the curve is taken as zero,
+// frequencies and densities vanish there
+func FromCurve(source string, grid, heights []float64) Analysis {
+	var analysis = Analysis{Source: source, Grid: grid, Heights: heights}
+
+	// runs of equal heights: a plateau is one peak, not one per point
+	var at = func(i int) float64 {
+		if i < 0 || i >= len(heights) {
+			return 0
+		}
+		return heights[i]
+	}
+
+	for from := 0; from < len(heights); {
+		var to = from
+		for to+1 < len(heights) && heights[to+1] == heights[from] {
+			to++
+		}
+
+		if heights[from] > at(from-1) && heights[from] > at(to+1) {
+			analysis.Peaks = append(analysis.Peaks, Peak{
+				Location:   (grid[from] + grid[to]) / 2,
+				From:       grid[from],
+				To:         grid[to],
+				Height:     heights[from],
+				Prominence: prominence(heights, from, to),
+			})
+		}
+
+		from = to + 1
+	}
+
+	sort.SliceStable(analysis.Peaks, func(i, j int) bool {
+		var a, b = analysis.Peaks[i], analysis.Peaks[j]
+		if a.Prominence != b.Prominence {
+			return a.Prominence > b.Prominence
+		}
+		return a.Height > b.Height
+	})
+	for i := range analysis.Peaks {
+		analysis.Peaks[i].Rank = i + 1
+	}
+
+	return analysis
+}
+
+// modes of a frequency table over integer variants. variants missing between the smallest and the largest
+// count as zero, so separated peaks stay apart
+func FromTable(d interfaces.IDistribution) Analysis {
+	var variants, occurences = d.GetVariants(), d.GetOccurences()
+
+	var counts = map[int]int{}
+	var low, high = math.MaxInt, math.MinInt
+	for i, x := range variants {
+		if occurences[i] == 0 {
+			continue
+		}
+		counts[x] += occurences[i]
+		low, high = min(low, x), max(high, x)
+	}
+
+	var grid, heights = []float64{}, []float64{}
+	for x := low; x <= high; x++ {
+		grid = append(grid, float64(x))
+		heights = append(heights, float64(counts[x]))
+	}
+
+	return FromCurve("frequency table", grid, heights)
+}
+
+// modes of grouped data: the classes' frequency densities (frequency / width) at their midpoints.
+// a peak's From and To are midpoints of its classes
+func FromGrouped(table grouped.Table) Analysis {
+	var grid, heights = make([]float64, len(table)), make([]float64, len(table))
+	for i, c := range table {
+		grid[i] = c.Midpoint()
+		heights[i] = float64(c.Frequency) / c.Width()
+	}
+
+	return FromCurve("grouped table", grid, heights)
+}
+
+// modes of a continuous sample as peaks of its kernel density estimate
+func FromSample(values interfaces.ISample, kernel density.Kernel, rule density.Bandwidth) (Analysis, error) {
+	kde, err := density.New(values, kernel, rule)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	return FromDensity(kde)
+}
+
+func FromDensity(kde density.KDE) (Analysis, error) {
+	grid, heights, err := kde.Curve(GridPoints)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	var source = fmt.Sprintf("kernel density (%s, %s bandwidth %.4g)", kde.Kernel.Name, kde.Rule, kde.Bandwidth)
+	return FromCurve(source, grid, heights), nil
+}
+
+// the most prominent peak
+func (a Analysis) Primary() (Peak, error) {
+	if len(a.Peaks) == 0 {
+		return Peak{}, errors.New("mode: the curve has no peak")
+	}
+	return a.Peaks[0], nil
+}
+
+// the peaks that reach the greatest height, the modes in the strict sense
+func (a Analysis) Highest() []Peak {
+	var highest = 0.0
+	for _, p := range a.Peaks {
+		highest = math.Max(highest, p.Height)
+	}
+
+	var peaks = []Peak{}
+	for _, p := range a.Peaks {
+		if p.Height == highest {
+			peaks = append(peaks, p)
+		}
+	}
+	return peaks
+}
+
+// the remaining local peaks
+func (a Analysis) Local() []Peak {
+	var highest = a.Highest()
+	var peaks = []Peak{}
+	for _, p := range a.Peaks {
+		if len(highest) == 0 || p.Height < highest[0].Height {
+			peaks = append(peaks, p)
+		}
+	}
+	return peaks
+}
+
+// peaks whose prominence is at least fraction of the highest peak, e.g. 0.05 drops the ripples
+// a narrow bandwidth leaves on noisy data
+func (a Analysis) Significant(fraction float64) []Peak {
+	var highest = a.Highest()
+	var peaks = []Peak{}
+	for _, p := range a.Peaks {
+		if len(highest) > 0 && p.Prominence >= fraction*highest[0].Height {
+			peaks = append(peaks, p)
+		}
+	}
+	return peaks
+}
+
+func (a Analysis) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Modes of the %s\n", a.Source)
+
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Rank\t Mode\t Modal values\t Height\t Prominence\t")
+	for _, p := range a.Peaks {
+		var span = fmt.Sprintf("%.4g", p.From)
+		if p.To != p.From {
+			span = fmt.Sprintf("%.4g – %.4g", p.From, p.To)
+		}
+		fmt.Fprintf(writer, " %d\t %.4g\t %s\t %.4g\t %.4g\t\n", p.Rank, p.Location, span, p.Height, p.Prominence)
+	}
+
+	return writer.Flush()
+}
+
+// height above the higher of the two lowest points on the way to a higher peak on either side
+// (or to the end of the curve, where it falls to zero)
+func prominence(heights []float64, from, to int) float64 {
+	var height = heights[from]
+
+	var base = func(start, step int) float64 {
+		var lowest = height
+		for i := start; i >= 0 && i < len(heights); i += step {
+			if heights[i] > height {
+				return lowest
+			}
+			lowest = math.Min(lowest, heights[i])
+		}
+		return 0
+	}
+
+	return height - math.Max(base(from-1, -1), base(to+1, 1))
+}
@@ -0,0 +1,184 @@
+package shared
+
+import (
+	"math"
+	"sort"
+)
+
+type Settings struct {
+	// stop when the simplex values and vertices are this close, relative to their size
+	Tolerance float64
+	// 0 means 1000 per dimension
+	MaxIterations int
+}
+
+func DefaultSettings() Settings {
+	return Settings{Tolerance: 1e-10}
+}
+
+type Result struct {
+	X          []float64
+	Value      float64
+	Iterations int
+	Converged  bool
+}
+
+// nelder–mead downhill simplex minimising f from start. f may return +Inf outside its domain,
+// the simplex then simply moves away from there
+func NelderMead(f func(x []float64) float64, start []float64, settings Settings) Result {
+	const reflection, expansion, contraction, shrink = 1.0, 2.0, 0.5, 0.5
+
+	var n = len(start)
+	var maxIterations = settings.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 1000 * n
+	}
+
+	// initial simplex: start plus a 5% step along every axis (fminsearch's choice)
+	var vertices = make([][]float64, n+1)
+	var values = make([]float64, n+1)
+	vertices[0] = append([]float64{}, start...)
+	for i := 0; i < n; i++ {
+		var v = append([]float64{}, start...)
+		if v[i] != 0 {
+			v[i] *= 1.05
+		} else {
+			v[i] = 0.00025
+		}
+		vertices[i+1] = v
+	}
+	for i, v := range vertices {
+		values[i] = f(v)
+	}
+
+	var point = func(centroid, towards []float64, t float64) []float64 {
+		var p = make([]float64, n)
+		for j := range p {
+			p[j] = centroid[j] + t*(towards[j]-centroid[j])
+		}
+		return p
+	}
+
+	var iteration = 0
+	var converged = false
+	for ; iteration < maxIterations; iteration++ {
+		var order = make([]int, n+1)
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+		var sortedVertices = make([][]float64, n+1)
+		var sortedValues = make([]float64, n+1)
+		for i, o := range order {
+			sortedVertices[i], sortedValues[i] = vertices[o], values[o]
+		}
+		vertices, values = sortedVertices, sortedValues
+
+		if spread(vertices, values) <= settings.Tolerance {
+			converged = true
+			break
+		}
+
+		var centroid = make([]float64, n)
+		for _, v := range vertices[:n] {
+			for j := range centroid {
+				centroid[j] += v[j] / float64(n)
+			}
+		}
+
+		var worst = vertices[n]
+		var reflected = point(centroid, worst, -reflection)
+		var fr = f(reflected)
+
+		switch {
+		case fr < values[0]:
+			var expanded = point(centroid, worst, -expansion)
+			if fe := f(expanded); fe < fr {
+				vertices[n], values[n] = expanded, fe
+			} else {
+				vertices[n], values[n] = reflected, fr
+			}
+
+		case fr < values[n-1]:
+			vertices[n], values[n] = reflected, fr
+
+		default:
+			// contract towards the better of the worst vertex and its reflection
+			var contracted []float64
+			if fr < values[n] {
+				contracted = point(centroid, reflected, contraction)
+			} else {
+				contracted = point(centroid, worst, contraction)
+			}
+
+			if fc := f(contracted); fc < math.Min(fr, values[n]) {
+				vertices[n], values[n] = contracted, fc
+				continue
+			}
+
+			for i := 1; i <= n; i++ {
+				vertices[i] = point(vertices[0], vertices[i], shrink)
+				values[i] = f(vertices[i])
+			}
+		}
+	}
+
+	var best = 0
+	for i := range values {
+		if values[i] < values[best] {
+			best = i
+		}
+	}
+
+	return Result{X: vertices[best], Value: values[best], Iterations: iteration, Converged: converged}
+}
+
+// central-difference hessian of f at x, steps scale with |x|
+func Hessian(f func(x []float64) float64, x []float64) [][]float64 {
+	var n = len(x)
+	var h = make([]float64, n)
+	for i := range h {
+		h[i] = 1e-4 * math.Max(math.Abs(x[i]), 1e-2)
+	}
+
+	var at = func(di, dj int, si, sj float64) float64 {
+		var p = append([]float64{}, x...)
+		p[di] += si * h[di]
+		p[dj] += sj * h[dj]
+		return f(p)
+	}
+
+	var hessian = make([][]float64, n)
+	for i := range hessian {
+		hessian[i] = make([]float64, n)
+	}
+
+	var center = f(x)
+	for i := 0; i < n; i++ {
+		var plus = append([]float64{}, x...)
+		var minus = append([]float64{}, x...)
+		plus[i] += h[i]
+		minus[i] -= h[i]
+		hessian[i][i] = (f(plus) - 2*center + f(minus)) / (h[i] * h[i])
+
+		for j := i + 1; j < n; j++ {
+			var value = (at(i, j, 1, 1) - at(i, j, 1, -1) - at(i, j, -1, 1) + at(i, j, -1, -1)) / (4 * h[i] * h[j])
+			hessian[i][j], hessian[j][i] = value, value
+		}
+	}
+
+	return hessian
+}
+
+// largest gap between the best vertex and the others, in value and position
+func spread(vertices [][]float64, values []float64) float64 {
+	var gap = 0.0
+	for i := 1; i < len(vertices); i++ {
+		gap = math.Max(gap, math.Abs(values[i]-values[0])/math.Max(1, math.Abs(values[0])))
+		for j := range vertices[i] {
+			gap = math.Max(gap, math.Abs(vertices[i][j]-vertices[0][j])/math.Max(1, math.Abs(vertices[0][j])))
+		}
+	}
+	return gap
+}
@@ -0,0 +1,57 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"shared/interfaces"
+
+	distribution "shared/models/Distribution"
+)
+
+// squared mahalanobis distance of every point from the centroid, D² = (p − μ)ᵀ S⁻¹ (p − μ) with S the
+// sample covariance. for bivariate normal points D² is about χ² on 2 df, points beyond its 1 − α quantile
+// are flagged. it sees points that are unusual together, not only in x or in y alone
+func Mahalanobis(points []interfaces.IPoint, alpha float64) (Detection, error) {
+	var n = len(points)
+	if n < 3 {
+		return Detection{}, errors.New("outlier: mahalanobis distance needs three or more points")
+	}
+
+	var mx, my = 0.0, 0.0
+	for _, p := range points {
+		mx += float64(p.GetX())
+		my += float64(p.GetY())
+	}
+	mx /= float64(n)
+	my /= float64(n)
+
+	var sxx, syy, sxy = 0.0, 0.0, 0.0
+	for _, p := range points {
+		var dx, dy = float64(p.GetX()) - mx, float64(p.GetY()) - my
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	sxx /= float64(n - 1)
+	syy /= float64(n - 1)
+	sxy /= float64(n - 1)
+
+	var determinant = sxx*syy - sxy*sxy
+	if determinant <= 1e-12*sxx*syy || sxx == 0 || syy == 0 {
+		return Detection{}, errors.New("outlier: the points lie on a line, their covariance is singular")
+	}
+
+	var detection = Detection{
+		Method:    fmt.Sprintf("Mahalanobis D² (α = %g)", alpha),
+		Threshold: distribution.ChiSquared{K: 2}.Quantile(1 - alpha),
+		Lower:     math.NaN(),
+		Upper:     math.NaN(),
+	}
+	for _, p := range points {
+		var dx, dy = float64(p.GetX()) - mx, float64(p.GetY()) - my
+		detection.Scores = append(detection.Scores, (syy*dx*dx-2*sxy*dx*dy+sxx*dy*dy)/determinant)
+	}
+
+	return flag(detection), nil
+}
@@ -0,0 +1,184 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"shared/interfaces"
+	"text/tabwriter"
+
+	sample "shared/models/Sample"
+)
+
+const (
+	// |z| beyond three standard deviations
+	ZThreshold = 3
+	// iglewicz and hoaglin's cut-off for the modified z-score
+	ModifiedZThreshold = 3.5
+	// tukey's inner fences lie 1.5 IQR beyond the quartiles, the outer ones 3 IQR
+	InnerFence = 1.5
+	OuterFence = 3
+	// 0.6745 = Φ⁻¹(3/4) makes the MAD estimate σ for normal data
+	madConsistency = 0.6745
+)
+
+// what a detector flagged and why
+type Detection struct {
+	Method string
+	// one per input value in input order, signed where the rule has a direction
+	Scores []float64
+	// positions of the flagged values, ascending
+	Indices []int
+	// what |score| was compared with
+	Threshold float64
+	// the same rule in the units of the data: values outside [Lower, Upper] are flagged.
+	// NaN when the rule has no fixed bounds (dixon, the ESD, mahalanobis)
+	Lower float64
+	Upper float64
+	// the tests removing one value at a time, in order
+	Steps []Step
+}
+
+// one round of a sequential test: the most extreme value left, its statistic and the critical value
+type Step struct {
+	Index     int
+	Statistic float64
+	Critical  float64
+}
+
+// (x − mean) / sd. the mean and sd are themselves pulled by the outliers, in small samples a single
+// one can never reach |z| = 3: the largest possible |z| is (n − 1) / √n
+func ZScore(values interfaces.ISample, threshold float64) (Detection, error) {
+	var data = values.GetValues()
+	if len(data) < 2 {
+		return Detection{}, errors.New("outlier: the z-score needs two or more values")
+	}
+
+	var mean, sd = sample.Mean(data), sample.StdDev(data)
+	if sd == 0 {
+		return Detection{}, errors.New("outlier: every value is the same")
+	}
+
+	var detection = Detection{
+		Method:    "z-score",
+		Threshold: threshold,
+		Lower:     mean - threshold*sd,
+		Upper:     mean + threshold*sd,
+	}
+	for _, x := range data {
+		detection.Scores = append(detection.Scores, (x-mean)/sd)
+	}
+
+	return flag(detection), nil
+}
+
+// 0.6745 (x − median) / MAD, robust to the outliers it looks for
+func ModifiedZScore(values interfaces.ISample, threshold float64) (Detection, error) {
+	var data = values.GetValues()
+	if len(data) == 0 {
+		return Detection{}, errors.New("outlier: no values")
+	}
+
+	var median = sample.Median(data)
+	var deviations = make([]float64, len(data))
+	for i, x := range data {
+		deviations[i] = math.Abs(x - median)
+	}
+	var mad = sample.Median(deviations)
+	if mad == 0 {
+		return Detection{}, errors.New("outlier: the MAD is zero, half the values or more are equal")
+	}
+
+	var detection = Detection{
+		Method:    "modified z-score",
+		Threshold: threshold,
+		Lower:     median - threshold*mad/madConsistency,
+		Upper:     median + threshold*mad/madConsistency,
+	}
+	for _, x := range data {
+		detection.Scores = append(detection.Scores, madConsistency*(x-median)/mad)
+	}
+
+	return flag(detection), nil
+}
+
+// tukey's fences Q1 − k IQR and Q3 + k IQR. a value scores how many IQRs it lies outside the box,
+// negative below Q1 and 0 within it
+func IQRFences(values interfaces.ISample, k float64) (Detection, error) {
+	var data = values.GetValues()
+	if len(data) == 0 {
+		return Detection{}, errors.New("outlier: no values")
+	}
+
+	var q1, q3 = sample.Quantile(data, 0.25), sample.Quantile(data, 0.75)
+	var iqr = q3 - q1
+	if iqr == 0 {
+		return Detection{}, errors.New("outlier: the interquartile range is zero")
+	}
+
+	var detection = Detection{
+		Method:    fmt.Sprintf("IQR fences (k = %g)", k),
+		Threshold: k,
+		Lower:     q1 - k*iqr,
+		Upper:     q3 + k*iqr,
+	}
+	for _, x := range data {
+		var score = 0.0
+		switch {
+		case x > q3:
+			score = (x - q3) / iqr
+		case x < q1:
+			score = (x - q1) / iqr
+		}
+		detection.Scores = append(detection.Scores, score)
+	}
+
+	return flag(detection), nil
+}
+
+// flagged values of several detections side by side. values may be nil (points), then only the positions show
+func WriteTable(w io.Writer, values []float64, detections ...Detection) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Method\t Threshold\t Lower\t Upper\t Flagged\t")
+	for _, d := range detections {
+		var flagged = ""
+		for j, i := range d.Indices {
+			if j > 0 {
+				flagged += ", "
+			}
+			if values != nil {
+				flagged += fmt.Sprintf("#%d = %g (%.2f)", i, values[i], d.Scores[i])
+			} else {
+				flagged += fmt.Sprintf("#%d (%.2f)", i, d.Scores[i])
+			}
+		}
+		if flagged == "" {
+			flagged = "none"
+		}
+
+		fmt.Fprintf(writer, " %s\t %.4f\t %s\t %s\t %s\t\n", d.Method, d.Threshold, bound(d.Lower), bound(d.Upper), flagged)
+	}
+
+	return writer.Flush()
+}
+
+// every value whose |score| exceeds the threshold
+func flag(d Detection) Detection {
+	for i, s := range d.Scores {
+		if math.Abs(s) > d.Threshold {
+			d.Indices = append(d.Indices, i)
+		}
+	}
+	return d
+}
+
+func bound(x float64) string {
+	if math.IsNaN(x) {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", x)
+}
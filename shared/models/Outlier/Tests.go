@@ -0,0 +1,214 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"shared/interfaces"
+	"sort"
+
+	distribution "shared/models/Distribution"
+	sample "shared/models/Sample"
+)
+
+// levels of the dixon table
+var DixonLevels = []float64{0.10, 0.05, 0.01}
+
+// critical values of dixon's Q = gap / range for 3..10 values, DixonCritical[n−3][j] at DixonLevels[j].
+// the suspect is whichever end is further from its neighbour (rorabacher 1991, two-sided)
+var DixonCritical = [][]float64{
+	{0.941, 0.970, 0.994},
+	{0.765, 0.829, 0.926},
+	{0.642, 0.710, 0.821},
+	{0.560, 0.625, 0.740},
+	{0.507, 0.568, 0.680},
+	{0.468, 0.526, 0.634},
+	{0.437, 0.493, 0.598},
+	{0.412, 0.466, 0.568},
+}
+
+// grubbs' test of the one value furthest from the mean, G = max |x − mean| / s against
+// (n − 1)/√n · √(t² / (n − 2 + t²)) with t the upper α/2n quantile of student's t on n − 2 df.
+// it assumes the rest of the sample is normal
+func Grubbs(values interfaces.ISample, alpha float64) (Detection, error) {
+	var data = values.GetValues()
+	if len(data) < 3 {
+		return Detection{}, errors.New("outlier: grubbs' test needs three or more values")
+	}
+
+	var mean, sd = sample.Mean(data), sample.StdDev(data)
+	if sd == 0 {
+		return Detection{}, errors.New("outlier: every value is the same")
+	}
+
+	var critical = grubbsCritical(len(data), alpha)
+	var detection = Detection{
+		Method:    fmt.Sprintf("Grubbs (α = %g)", alpha),
+		Threshold: critical,
+		Lower:     mean - critical*sd,
+		Upper:     mean + critical*sd,
+	}
+
+	var extreme = 0
+	for i, x := range data {
+		detection.Scores = append(detection.Scores, (x-mean)/sd)
+		if math.Abs(detection.Scores[i]) > math.Abs(detection.Scores[extreme]) {
+			extreme = i
+		}
+	}
+
+	detection.Steps = []Step{{Index: extreme, Statistic: math.Abs(detection.Scores[extreme]), Critical: critical}}
+	if detection.Steps[0].Statistic > critical {
+		detection.Indices = []int{extreme}
+	}
+
+	return detection, nil
+}
+
+// dixon's Q test for 3 to 10 values: the gap between the more isolated end and its neighbour over the range.
+// only the minimum and the maximum are scored, every other value scores 0
+func Dixon(values interfaces.ISample, alpha float64) (Detection, error) {
+	var data = values.GetValues()
+	var n = len(data)
+	if n < 3 || n > 2+len(DixonCritical) {
+		return Detection{}, fmt.Errorf("outlier: dixon's Q is tabulated for 3 to %d values, not %d", 2+len(DixonCritical), n)
+	}
+
+	var level = -1
+	for j, l := range DixonLevels {
+		if math.Abs(l-alpha) < 1e-12 {
+			level = j
+		}
+	}
+	if level < 0 {
+		return Detection{}, fmt.Errorf("outlier: α = %g is not in the dixon table", alpha)
+	}
+
+	var order = make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return data[order[a]] < data[order[b]] })
+
+	var low, high = data[order[0]], data[order[n-1]]
+	if high == low {
+		return Detection{}, errors.New("outlier: every value is the same")
+	}
+
+	var critical = DixonCritical[n-3][level]
+	var detection = Detection{
+		Method:    fmt.Sprintf("Dixon's Q (α = %g)", alpha),
+		Scores:    make([]float64, n),
+		Threshold: critical,
+		Lower:     math.NaN(),
+		Upper:     math.NaN(),
+	}
+	detection.Scores[order[0]] = -(data[order[1]] - low) / (high - low)
+	detection.Scores[order[n-1]] = (high - data[order[n-2]]) / (high - low)
+
+	var suspect = order[n-1]
+	if -detection.Scores[order[0]] > detection.Scores[order[n-1]] {
+		suspect = order[0]
+	}
+	detection.Steps = []Step{{Index: suspect, Statistic: math.Abs(detection.Scores[suspect]), Critical: critical}}
+	if detection.Steps[0].Statistic > critical {
+		detection.Indices = []int{suspect}
+	}
+
+	return detection, nil
+}
+
+// rosner's generalized ESD for up to maxOutliers outliers: the value furthest from the mean is removed
+// maxOutliers times, R_i = max |x − mean| / s of what is left against
+// λ_i = (n − i) t / √((n − i − 1 + t²)(n − i + 1)), t the upper α/2(n − i + 1) quantile on n − i − 1 df.
+// the outliers are the values removed up to the last step with R_i > λ_i, even when earlier ones fell short.
+// a removed value scores its R_i (signed), the others their z-score among the values never removed.
+// Threshold is the critical value of that last step
+func GeneralizedESD(values interfaces.ISample, maxOutliers int, alpha float64) (Detection, error) {
+	var data = values.GetValues()
+	var n = len(data)
+	if maxOutliers < 1 || maxOutliers > n-2 {
+		return Detection{}, fmt.Errorf("outlier: %d values allow between 1 and %d outliers to be tested", n, n-2)
+	}
+
+	if sample.StdDev(data) == 0 {
+		return Detection{}, errors.New("outlier: every value is the same")
+	}
+
+	var detection = Detection{
+		Method: fmt.Sprintf("generalized ESD (≤ %d, α = %g)", maxOutliers, alpha),
+		Scores: make([]float64, n),
+		Lower:  math.NaN(),
+		Upper:  math.NaN(),
+	}
+
+	var left = make([]int, n)
+	for i := range left {
+		left[i] = i
+	}
+
+	var rest = func() []float64 {
+		var kept = make([]float64, len(left))
+		for j, k := range left {
+			kept[j] = data[k]
+		}
+		return kept
+	}
+
+	var outliers = 0
+	for i := 1; i <= maxOutliers; i++ {
+		var kept = rest()
+		var m, s = sample.Mean(kept), sample.StdDev(kept)
+		if s == 0 {
+			break
+		}
+
+		var extreme = 0
+		for j := range kept {
+			if math.Abs(kept[j]-m) > math.Abs(kept[extreme]-m) {
+				extreme = j
+			}
+		}
+
+		var critical = esdCritical(n, i, alpha)
+		detection.Scores[left[extreme]] = (kept[extreme] - m) / s
+		detection.Steps = append(detection.Steps, Step{Index: left[extreme], Statistic: math.Abs(kept[extreme]-m) / s, Critical: critical})
+		if detection.Steps[i-1].Statistic > critical {
+			outliers = i
+		}
+		left = append(left[:extreme], left[extreme+1:]...)
+	}
+
+	var kept = rest()
+	var m, s = sample.Mean(kept), sample.StdDev(kept)
+	for _, k := range left {
+		if s > 0 {
+			detection.Scores[k] = (data[k] - m) / s
+		}
+	}
+
+	detection.Threshold = detection.Steps[0].Critical
+	if outliers > 0 {
+		detection.Threshold = detection.Steps[outliers-1].Critical
+	}
+	for _, step := range detection.Steps[:outliers] {
+		detection.Indices = append(detection.Indices, step.Index)
+	}
+	sort.Ints(detection.Indices)
+
+	return detection, nil
+}
+
+func grubbsCritical(n int, alpha float64) float64 {
+	var nf = float64(n)
+	var t = distribution.StudentT{Nu: nf - 2}.Quantile(1 - alpha/(2*nf))
+	return (nf - 1) / math.Sqrt(nf) * math.Sqrt(t*t/(nf-2+t*t))
+}
+
+// λ_i of rosner (1983)
+func esdCritical(n, i int, alpha float64) float64 {
+	var remaining = float64(n - i + 1)
+	var df = remaining - 2
+	var t = distribution.StudentT{Nu: df}.Quantile(1 - alpha/(2*remaining))
+	return (remaining - 1) * t / math.Sqrt((df+t*t)*remaining)
+}
@@ -0,0 +1,315 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"shared/interfaces"
+
+	distribution "shared/models/Distribution"
+	hypothesis "shared/models/Hypothesis"
+	sample "shared/models/Sample"
+	simulation "shared/models/Simulation"
+)
+
+// arrangements up to this many are all enumerated, beyond it config.Replications random ones are drawn
+const ExactLimit = 1_000_000
+
+// statistic over the groups of one arrangement. the slices are reused between calls, do not keep them
+type GroupStatistic func(groups [][]float64) float64
+
+// statistic over paired or matched observations, x[i] belongs with y[i]
+type PairStatistic func(x, y []float64) float64
+
+type Result struct {
+	Statistic float64
+	PValue    float64
+	Exact     bool
+	// arrangements enumerated when exact (the observed one included), drawn otherwise
+	Permutations int
+	// monte carlo standard error of PValue, 0 when exact
+	MCSE        float64
+	Alternative hypothesis.Alternative
+}
+
+// the groups are exchangeable under H0: every split of the pooled values into groups of the same sizes
+// is equally likely. two-sided p-values double the smaller tail, statistics that are large under every
+// alternative (like BetweenGroups) should use Greater
+func Groups(statistic GroupStatistic, alternative hypothesis.Alternative, config simulation.Config, groups ...interfaces.ISample) (Result, error) {
+	if len(groups) < 2 {
+		return Result{}, errors.New("permutation: at least two groups are needed")
+	}
+
+	var pooled = []float64{}
+	var sizes = make([]int, len(groups))
+	var observed = make([][]float64, len(groups))
+	for i, group := range groups {
+		observed[i] = group.GetValues()
+		if len(observed[i]) == 0 {
+			return Result{}, fmt.Errorf("permutation: group %d is empty", i)
+		}
+		sizes[i] = len(observed[i])
+		pooled = append(pooled, observed[i]...)
+	}
+
+	var t = newTally(statistic(observed))
+
+	if distribution.Multinomial(sizes) <= ExactLimit {
+		var arrangement = make([][]float64, len(sizes))
+		var assign func(i int)
+		assign = func(i int) {
+			if i == len(pooled) {
+				t.add(statistic(arrangement))
+				return
+			}
+
+			for g := range sizes {
+				if len(arrangement[g]) == sizes[g] {
+					continue
+				}
+				arrangement[g] = append(arrangement[g], pooled[i])
+				assign(i + 1)
+				arrangement[g] = arrangement[g][:len(arrangement[g])-1]
+			}
+		}
+		assign(0)
+
+		return t.exact(alternative), nil
+	}
+
+	draws, err := simulation.Replicate(config, func(rnd *rand.Rand) float64 {
+		var shuffled = append([]float64{}, pooled...)
+		rnd.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		return statistic(split(shuffled, sizes))
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return t.monteCarlo(draws, alternative)
+}
+
+// x[i] and y[i] are exchangeable under H0 (the same unit under two treatments),
+// every arrangement swaps some of the pairs
+func Paired(statistic PairStatistic, alternative hypothesis.Alternative, config simulation.Config, x, y interfaces.ISample) (Result, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if len(xs) != len(ys) {
+		return Result{}, errors.New("permutation: x and y must have the same length")
+	}
+	if len(xs) == 0 {
+		return Result{}, errors.New("permutation: no pairs")
+	}
+
+	var t = newTally(statistic(xs, ys))
+	var n = len(xs)
+
+	if math.Ldexp(1, n) <= ExactLimit {
+		for mask := 0; mask < 1<<n; mask++ {
+			t.add(statistic(swapped(xs, ys, func(i int) bool { return mask&(1<<i) != 0 })))
+		}
+		return t.exact(alternative), nil
+	}
+
+	draws, err := simulation.Replicate(config, func(rnd *rand.Rand) float64 {
+		return statistic(swapped(xs, ys, func(int) bool { return rnd.Intn(2) == 1 }))
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return t.monteCarlo(draws, alternative)
+}
+
+// x and y are independent under H0: y is shuffled against x, e.g. for Correlation
+func Association(statistic PairStatistic, alternative hypothesis.Alternative, config simulation.Config, x, y interfaces.ISample) (Result, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if len(xs) != len(ys) {
+		return Result{}, errors.New("permutation: x and y must have the same length")
+	}
+	if len(xs) < 2 {
+		return Result{}, errors.New("permutation: at least two pairs are needed")
+	}
+
+	var t = newTally(statistic(xs, ys))
+	var n = len(xs)
+
+	if distribution.Factorial(n) <= ExactLimit {
+		// heap's algorithm, every permutation of y exactly once
+		var perm = append([]float64{}, ys...)
+		var c = make([]int, n)
+		t.add(statistic(xs, perm))
+		for i := 0; i < n; {
+			if c[i] < i {
+				if i%2 == 0 {
+					perm[0], perm[i] = perm[i], perm[0]
+				} else {
+					perm[c[i]], perm[i] = perm[i], perm[c[i]]
+				}
+				t.add(statistic(xs, perm))
+				c[i]++
+				i = 0
+			} else {
+				c[i] = 0
+				i++
+			}
+		}
+		return t.exact(alternative), nil
+	}
+
+	draws, err := simulation.Replicate(config, func(rnd *rand.Rand) float64 {
+		var perm = append([]float64{}, ys...)
+		rnd.Shuffle(n, func(i, j int) {
+			perm[i], perm[j] = perm[j], perm[i]
+		})
+		return statistic(xs, perm)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return t.monteCarlo(draws, alternative)
+}
+
+// mean of the first group minus mean of the second
+func DifferenceInMeans(groups [][]float64) float64 {
+	return sample.Mean(groups[0]) - sample.Mean(groups[1])
+}
+
+// median of the first group minus median of the second
+func DifferenceInMedians(groups [][]float64) float64 {
+	return sample.Median(groups[0]) - sample.Median(groups[1])
+}
+
+// Σ nᵢ (meanᵢ − grand mean)², equivalent to the one-way anova F under permutation
+func BetweenGroups(groups [][]float64) float64 {
+	var total, n = 0.0, 0
+	for _, g := range groups {
+		for _, v := range g {
+			total += v
+		}
+		n += len(g)
+	}
+
+	var grand = total / float64(n)
+	var ss = 0.0
+	for _, g := range groups {
+		var diff = sample.Mean(g) - grand
+		ss += float64(len(g)) * diff * diff
+	}
+
+	return ss
+}
+
+// mean of x[i] − y[i]
+func MeanDifference(x, y []float64) float64 {
+	var sum = 0.0
+	for i := range x {
+		sum += x[i] - y[i]
+	}
+	return sum / float64(len(x))
+}
+
+// pearson correlation
+func Correlation(x, y []float64) float64 {
+	var mx, my = sample.Mean(x), sample.Mean(y)
+	var sxy, sxx, syy = 0.0, 0.0, 0.0
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// arrangements at least as extreme as the observed statistic in either direction,
+// with a relative tolerance so ties survive rounding
+type tally struct {
+	observed  float64
+	tolerance float64
+	below     int
+	above     int
+	total     int
+}
+
+func newTally(observed float64) *tally {
+	return &tally{observed: observed, tolerance: 1e-9 * math.Max(1, math.Abs(observed))}
+}
+
+func (t *tally) add(s float64) {
+	t.total++
+	if s <= t.observed+t.tolerance {
+		t.below++
+	}
+	if s >= t.observed-t.tolerance {
+		t.above++
+	}
+}
+
+func (t *tally) exact(alternative hypothesis.Alternative) Result {
+	var total = float64(t.total)
+	return Result{
+		Statistic:    t.observed,
+		PValue:       tailPValue(float64(t.below)/total, float64(t.above)/total, alternative),
+		Exact:        true,
+		Permutations: t.total,
+		Alternative:  alternative,
+	}
+}
+
+// the observed arrangement counts as one of the draws, so the p-value is never zero
+func (t *tally) monteCarlo(draws []float64, alternative hypothesis.Alternative) (Result, error) {
+	if len(draws) == 0 {
+		return Result{}, errors.New("permutation: too many arrangements to enumerate and no replications configured")
+	}
+
+	for _, s := range draws {
+		t.add(s)
+	}
+
+	var b = float64(t.total)
+	var p = tailPValue((float64(t.below)+1)/(b+1), (float64(t.above)+1)/(b+1), alternative)
+
+	return Result{
+		Statistic:    t.observed,
+		PValue:       p,
+		Permutations: t.total,
+		MCSE:         math.Sqrt(p * (1 - p) / b),
+		Alternative:  alternative,
+	}, nil
+}
+
+func tailPValue(below, above float64, alternative hypothesis.Alternative) float64 {
+	switch alternative {
+	case hypothesis.Greater:
+		return above
+	case hypothesis.Less:
+		return below
+	default:
+		return math.Min(1, 2*math.Min(below, above))
+	}
+}
+
+// copies of x and y with the pairs selected by flip exchanged
+func swapped(xs, ys []float64, flip func(i int) bool) ([]float64, []float64) {
+	var a, b = make([]float64, len(xs)), make([]float64, len(ys))
+	for i := range xs {
+		a[i], b[i] = xs[i], ys[i]
+		if flip(i) {
+			a[i], b[i] = ys[i], xs[i]
+		}
+	}
+	return a, b
+}
+
+func split(values []float64, sizes []int) [][]float64 {
+	var groups = make([][]float64, len(sizes))
+	var start = 0
+	for g, size := range sizes {
+		groups[g] = values[start : start+size]
+		start += size
+	}
+	return groups
+}
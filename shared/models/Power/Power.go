@@ -0,0 +1,271 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"text/tabwriter"
+
+	distribution "shared/models/Distribution"
+	hypothesis "shared/models/Hypothesis"
+	simulation "shared/models/Simulation"
+)
+
+// sample sizes above this are treated as unreachable
+const MaxSampleSize = 10_000_000
+
+// a test whose power can be evaluated at sample size n for a given effect size.
+// n is per group for two-group designs
+type Test interface {
+	Power(n int, effect float64) float64
+	MinSampleSize() int
+}
+
+type Design int
+
+const (
+	OneSample Design = iota
+	Paired
+	TwoSample
+)
+
+// z-test on means with known variance, effect is cohen's d = (mu1 - mu0) / sigma
+type ZTest struct {
+	Alpha       float64
+	Alternative hypothesis.Alternative
+	Design      Design
+}
+
+func (t ZTest) Power(n int, effect float64) float64 {
+	return normalPower(effect*math.Sqrt(effectiveN(t.Design, n)), t.Alpha, t.Alternative)
+}
+
+func (t ZTest) MinSampleSize() int {
+	return 1
+}
+
+// t-test on means, effect is cohen's d. power comes from the noncentral t distribution
+type TTest struct {
+	Alpha       float64
+	Alternative hypothesis.Alternative
+	Design      Design
+}
+
+func (t TTest) Power(n int, effect float64) float64 {
+	var df = float64(n - 1)
+	if t.Design == TwoSample {
+		df = float64(2*n - 2)
+	}
+
+	var central = distribution.StudentT{Nu: df}
+	var shifted = distribution.NoncentralT{Nu: df, Delta: effect * math.Sqrt(effectiveN(t.Design, n))}
+
+	switch t.Alternative {
+	case hypothesis.Greater:
+		return 1 - shifted.CDF(central.Quantile(1-t.Alpha))
+	case hypothesis.Less:
+		return shifted.CDF(central.Quantile(t.Alpha))
+	default:
+		var critical = central.Quantile(1 - t.Alpha/2)
+		return 1 - shifted.CDF(critical) + shifted.CDF(-critical)
+	}
+}
+
+func (t TTest) MinSampleSize() int {
+	return 2
+}
+
+// proportion test through the arcsine transform, effect is cohen's h (see CohenH).
+// OneSample compares against a fixed p0, TwoSample compares two groups of n
+type ProportionTest struct {
+	Alpha       float64
+	Alternative hypothesis.Alternative
+	Design      Design
+}
+
+func (t ProportionTest) Power(n int, effect float64) float64 {
+	return normalPower(effect*math.Sqrt(effectiveN(t.Design, n)), t.Alpha, t.Alternative)
+}
+
+func (t ProportionTest) MinSampleSize() int {
+	return 1
+}
+
+// h = 2·asin(√p1) − 2·asin(√p2)
+func CohenH(p1, p2 float64) float64 {
+	return 2*math.Asin(math.Sqrt(p1)) - 2*math.Asin(math.Sqrt(p2))
+}
+
+// test of H0: rho = 0 through fisher's z, effect is the true correlation rho
+type CorrelationTest struct {
+	Alpha       float64
+	Alternative hypothesis.Alternative
+}
+
+func (t CorrelationTest) Power(n int, effect float64) float64 {
+	return normalPower(math.Atanh(effect)*math.Sqrt(float64(n-3)), t.Alpha, t.Alternative)
+}
+
+func (t CorrelationTest) MinSampleSize() int {
+	return 4
+}
+
+// chi-squared goodness-of-fit or independence test with DF degrees of freedom.
+// effect is cohen's w = √Σ (p1 − p0)² / p0, the noncentrality is n·w²
+type ChiSquareTest struct {
+	Alpha float64
+	DF    int
+}
+
+func (t ChiSquareTest) Power(n int, effect float64) float64 {
+	var critical = distribution.ChiSquared{K: float64(t.DF)}.Quantile(1 - t.Alpha)
+	var shifted = distribution.NoncentralChiSquared{K: float64(t.DF), Lambda: float64(n) * effect * effect}
+	return 1 - shifted.CDF(critical)
+}
+
+func (t ChiSquareTest) MinSampleSize() int {
+	return 1
+}
+
+// w for the hypothesised probabilities p0 against the true probabilities p1
+func CohenW(p0, p1 []float64) float64 {
+	var sum = 0.0
+	for i := range p0 {
+		sum += (p1[i] - p0[i]) * (p1[i] - p0[i]) / p0[i]
+	}
+	return math.Sqrt(sum)
+}
+
+// power by simulation for tests without a closed form. Experiment draws a sample of size n
+// with the given effect from rnd and reports whether the test rejected H0.
+// the power is NaN without replications
+type SimulatedTest struct {
+	Replications int
+	Workers      int
+	Seed         int64
+	Minimum      int
+	Experiment   func(rnd *rand.Rand, n int, effect float64) bool
+}
+
+func (t SimulatedTest) Power(n int, effect float64) float64 {
+	if t.Replications <= 0 {
+		return math.NaN()
+	}
+
+	var config = simulation.Config{Replications: t.Replications, Workers: t.Workers, Seed: t.Seed}
+	report, err := simulation.Run(config, func(rnd *rand.Rand) simulation.Outcome {
+		return simulation.Outcome{Decisions: []simulation.Decision{
+			{Name: "power", Rejected: t.Experiment(rnd, n, effect), NullTrue: effect == 0},
+		}}
+	})
+	if err != nil {
+		return math.NaN()
+	}
+
+	return report.Tests[0].RejectionRate
+}
+
+func (t SimulatedTest) MinSampleSize() int {
+	return max(1, t.Minimum)
+}
+
+func effectiveN(design Design, n int) float64 {
+	if design == TwoSample {
+		return float64(n) / 2
+	}
+	return float64(n)
+}
+
+func normalPower(shift, alpha float64, alternative hypothesis.Alternative) float64 {
+	var z = distribution.StandardNormal()
+
+	switch alternative {
+	case hypothesis.Greater:
+		return z.Survival(z.Quantile(1-alpha) - shift)
+	case hypothesis.Less:
+		return z.CDF(z.Quantile(alpha) - shift)
+	default:
+		var critical = z.Quantile(1 - alpha/2)
+		return z.Survival(critical-shift) + z.CDF(-critical-shift)
+	}
+}
+
+// smallest n reaching the target power: doubling to bracket it, then bisection
+func SampleSize(test Test, effect, target float64) (int, error) {
+	if target <= 0 || target >= 1 {
+		return 0, errors.New("sample size: target power must be between 0 and 1")
+	}
+
+	var lo = test.MinSampleSize()
+	var power = test.Power(lo, effect)
+	if math.IsNaN(power) {
+		return 0, errors.New("sample size: the power is undefined, a simulated test needs replications")
+	}
+	if power >= target {
+		return lo, nil
+	}
+
+	var hi = lo * 2
+	for test.Power(hi, effect) < target {
+		lo = hi
+		hi *= 2
+		if hi > MaxSampleSize {
+			return 0, fmt.Errorf("sample size: power %.3f is not reached below n = %d", target, MaxSampleSize)
+		}
+	}
+
+	for hi-lo > 1 {
+		var mid = lo + (hi-lo)/2
+		if test.Power(mid, effect) >= target {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi, nil
+}
+
+type CurvePoint struct {
+	Effect float64
+	N      int
+	Power  float64
+}
+
+// power over a range of effect sizes at fixed n
+func Curve(test Test, n int, effects []float64) []CurvePoint {
+	var points = make([]CurvePoint, len(effects))
+	for i, effect := range effects {
+		points[i] = CurvePoint{Effect: effect, N: n, Power: test.Power(n, effect)}
+	}
+	return points
+}
+
+// count evenly spaced effect sizes from low to high
+func EffectRange(low, high float64, count int) []float64 {
+	if count < 2 {
+		return []float64{low}
+	}
+
+	var effects = make([]float64, count)
+	var step = (high - low) / float64(count-1)
+	for i := range effects {
+		effects[i] = low + float64(i)*step
+	}
+	return effects
+}
+
+func WriteCurve(w io.Writer, points []CurvePoint) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Effect\t n\t Power\t")
+	for _, p := range points {
+		fmt.Fprintf(writer, " %.4g\t %d\t %.4f\t\n", p.Effect, p.N, p.Power)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,16 @@
+package shared
+
+import "math/rand"
+
+// splitmix64 step over (base seed, stream index). neighbouring streams get unrelated seeds,
+// so replicate i draws the same numbers no matter which goroutine runs it
+func Seed(base int64, stream int) int64 {
+	var z = uint64(base) + uint64(stream+1)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return int64(z ^ (z >> 31))
+}
+
+func Make(base int64, stream int) *rand.Rand {
+	return rand.New(rand.NewSource(Seed(base, stream)))
+}
@@ -0,0 +1,178 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+
+	correction "shared/models/Correction"
+)
+
+// a generator's output in the two forms the tests need: the raw values in drawing order and the same values
+// as digits 0..Categories-1, which is how knuth states the classical tests
+type Stream struct {
+	Values     []float64
+	Digits     []int
+	Categories int
+}
+
+// outcome of one test. a test that cannot run on the stream (too short, no usable alphabet) is Skipped
+// with the reason in Note
+type Result struct {
+	Name string
+	// the null distribution of the statistic, e.g. "χ²(4)" or "N(0, 1)"
+	Reference string
+	Statistic float64
+	PValue    float64
+	// holm's adjustment of PValue for the other tests of the battery that ran, NaN when skipped
+	Adjusted float64
+	Skipped  bool
+	Note     string
+}
+
+type Report struct {
+	N          int
+	Categories int
+	Alpha      float64
+	Results    []Result
+}
+
+// draws of an integer generator over low..high, each value is its own digit. a draw outside the range
+// is an error, a generator that cannot keep to its range fails before any test runs
+func Integers(values []int, low, high int) (Stream, error) {
+	if high <= low {
+		return Stream{}, fmt.Errorf("randomness: the range %d..%d needs two or more values", low, high)
+	}
+
+	var stream = Stream{Categories: high - low + 1}
+	for _, v := range values {
+		if v < low || v > high {
+			return Stream{}, fmt.Errorf("randomness: %d is outside %d..%d", v, low, high)
+		}
+		stream.Values = append(stream.Values, float64(v))
+		stream.Digits = append(stream.Digits, v-low)
+	}
+
+	return stream, nil
+}
+
+// draws of a float generator over [low, high), the range cut into categories equal digits
+func Floats(values []float64, low, high float64, categories int) (Stream, error) {
+	if !(high > low) || math.IsInf(high-low, 0) {
+		return Stream{}, fmt.Errorf("randomness: [%g, %g) is not a finite range", low, high)
+	}
+	if categories < 2 {
+		return Stream{}, errors.New("randomness: two or more categories are needed")
+	}
+
+	var stream = Stream{Categories: categories}
+	for _, v := range values {
+		if !(v >= low && v < high) {
+			return Stream{}, fmt.Errorf("randomness: %g is outside [%g, %g)", v, low, high)
+		}
+		var digit = int(float64(categories) * (v - low) / (high - low))
+		stream.Values = append(stream.Values, v)
+		stream.Digits = append(stream.Digits, min(digit, categories-1))
+	}
+
+	return stream, nil
+}
+
+func (s Stream) N() int {
+	return len(s.Values)
+}
+
+// the whole battery, every test on the same stream. the p-values of the tests that ran are adjusted together
+// with holm's method, so a fair generator fails the battery with probability at most alpha and not once
+// per test
+func Run(stream Stream, alpha float64) Report {
+	var report = Report{N: stream.N(), Categories: stream.Categories, Alpha: alpha}
+
+	var tests = []struct {
+		name string
+		run  func(Stream) (Result, error)
+	}{
+		{"Frequency", Frequency},
+		{"Runs above/below mean", Runs},
+		{"Serial correlation", SerialCorrelation},
+		{"Gap", Gap},
+		{"Poker", Poker},
+		{"Coupon collector", CouponCollector},
+		{"Birthday spacings", BirthdaySpacings},
+	}
+
+	var ran = []int{}
+	var pValues = []float64{}
+	for _, test := range tests {
+		result, err := test.run(stream)
+		if err != nil {
+			result = Result{Name: test.name, Skipped: true, Note: err.Error()}
+		}
+		result.Adjusted = math.NaN()
+		if !result.Skipped {
+			ran = append(ran, len(report.Results))
+			pValues = append(pValues, result.PValue)
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if adjusted, err := correction.Adjust(pValues, correction.Holm); err == nil {
+		for j, i := range ran {
+			report.Results[i].Adjusted = adjusted[j]
+		}
+	}
+
+	return report
+}
+
+// the test on its own at alpha, without regard to the rest of the battery
+func (r Result) Passed(alpha float64) bool {
+	return !r.Skipped && r.PValue >= alpha
+}
+
+// no test that ran is rejected after holm's adjustment
+func (r Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Skipped && !(result.Adjusted > r.Alpha) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Report) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "%d values over %d categories, α = %g\n", r.N, r.Categories, r.Alpha)
+
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintln(writer, " Test\t Statistic\t Reference\t p-value\t Holm\t Verdict\t")
+	for _, result := range r.Results {
+		if result.Skipped {
+			fmt.Fprintf(writer, " %s\t -\t -\t -\t -\t skipped: %s\t\n", result.Name, result.Note)
+			continue
+		}
+
+		var verdict = "pass"
+		if !(result.Adjusted > r.Alpha) {
+			verdict = "FAIL"
+		}
+		if result.Note != "" {
+			verdict += " (" + result.Note + ")"
+		}
+		fmt.Fprintf(writer, " %s\t %.4f\t %s\t %.4f\t %.4f\t %s\t\n", result.Name, result.Statistic, result.Reference, result.PValue, result.Adjusted, verdict)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	var verdict = "pass"
+	if !r.Passed() {
+		verdict = "FAIL"
+	}
+	_, err := fmt.Fprintf(w, "Battery: %s (Holm-adjusted p-values against α = %g)\n", verdict, r.Alpha)
+	return err
+}
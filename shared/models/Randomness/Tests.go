@@ -0,0 +1,327 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	distribution "shared/models/Distribution"
+	gof "shared/models/GoodnessOfFit"
+	sample "shared/models/Sample"
+)
+
+const (
+	// digits per hand of the poker test
+	HandSize = 5
+	// the coupon collector test draws from an alphabet of at most this many digits
+	CouponAlphabet = 8
+	// birthday spacings combine digits into years of at least this many days
+	YearDays = 1 << 16
+)
+
+// every digit equally often: chi-squared over the categories, neighbouring ones merged while less than
+// five are expected
+func Frequency(s Stream) (Result, error) {
+	var counts = make([]int, s.Categories)
+	for _, d := range s.Digits {
+		counts[d]++
+	}
+
+	var expected = float64(s.N()) / float64(s.Categories)
+	var categories = make([]gof.Category, s.Categories)
+	for d, c := range counts {
+		categories[d] = gof.Category{Lower: float64(d), Upper: float64(d), Observed: c, Expected: expected}
+	}
+
+	return chiSquared("Frequency", categories)
+}
+
+// wald–wolfowitz: values above and below the mean should alternate neither too often nor too rarely.
+// the mean rather than the median splits the values, so a stream of few digits (coin flips) loses no
+// values to ties. values equal to it are left out
+func Runs(s Stream) (Result, error) {
+	if s.N() == 0 {
+		return Result{}, errors.New("no values")
+	}
+	var mean = sample.Mean(s.Values)
+
+	var runs, above, below = 0, 0, 0
+	var previous = 0
+	for _, v := range s.Values {
+		var side = 0
+		switch {
+		case v > mean:
+			side = 1
+			above++
+		case v < mean:
+			side = -1
+			below++
+		default:
+			continue
+		}
+		if side != previous {
+			runs++
+			previous = side
+		}
+	}
+	if above < 10 || below < 10 {
+		return Result{}, errors.New("fewer than 10 values on a side of the mean")
+	}
+
+	var n1, n2 = float64(above), float64(below)
+	var n = n1 + n2
+	var expected = 2*n1*n2/n + 1
+	var variance = 2 * n1 * n2 * (2*n1*n2 - n) / (n * n * (n - 1))
+	var z = (float64(runs) - expected) / math.Sqrt(variance)
+
+	return Result{
+		Name:      "Runs above/below mean",
+		Reference: "N(0, 1)",
+		Statistic: z,
+		PValue:    2 * distribution.StandardNormal().Survival(math.Abs(z)),
+	}, nil
+}
+
+// knuth's lag-one serial correlation coefficient, each value against the next and the last against the first.
+// under independence its mean is −1/(n − 1) and its variance n² / ((n − 1)² (n − 2))
+func SerialCorrelation(s Stream) (Result, error) {
+	var n = s.N()
+	if n < 3 {
+		return Result{}, errors.New("needs three or more values")
+	}
+
+	var sum, squares, products = 0.0, 0.0, 0.0
+	for i, v := range s.Values {
+		sum += v
+		squares += v * v
+		products += v * s.Values[(i+1)%n]
+	}
+
+	var nf = float64(n)
+	var denominator = nf*squares - sum*sum
+	if denominator <= 0 {
+		return Result{}, errors.New("every value is the same")
+	}
+
+	var c = (nf*products - sum*sum) / denominator
+	var mean = -1 / (nf - 1)
+	var sd = nf / ((nf - 1) * math.Sqrt(nf-2))
+	var z = (c - mean) / sd
+
+	return Result{
+		Name:      "Serial correlation",
+		Reference: "N(0, 1)",
+		Statistic: z,
+		PValue:    2 * distribution.StandardNormal().Survival(math.Abs(z)),
+		Note:      fmt.Sprintf("C = %.4f", c),
+	}, nil
+}
+
+// a digit in the lower half of the alphabet is a hit, with probability p. the gaps between hits
+// should be geometric, P(gap = r) = p (1 − p)^r
+func Gap(s Stream) (Result, error) {
+	var hits = s.Categories / 2
+	var p = float64(hits) / float64(s.Categories)
+
+	var gaps = []int{}
+	var length = -1
+	for _, d := range s.Digits {
+		if d < hits {
+			if length >= 0 {
+				gaps = append(gaps, length)
+			}
+			length = 0
+		} else if length >= 0 {
+			length++
+		}
+	}
+	if len(gaps) == 0 {
+		return Result{}, errors.New("no gaps")
+	}
+
+	var total = float64(len(gaps))
+	var categories = []gof.Category{}
+	var tail = 1.0
+	for r := 0; total*tail >= 5 && r < 1000; r++ {
+		categories = append(categories, gof.Category{Lower: float64(r), Upper: float64(r), Expected: total * p * tail})
+		tail *= 1 - p
+	}
+	categories = append(categories, gof.Category{Lower: float64(len(categories)), Upper: math.Inf(1), Expected: total * tail})
+
+	for _, g := range gaps {
+		categories[min(g, len(categories)-1)].Observed++
+	}
+
+	return chiSquared("Gap", categories)
+}
+
+// hands of five successive digits scored by how many different digits they hold
+func Poker(s Stream) (Result, error) {
+	var hands = s.N() / HandSize
+	if hands == 0 {
+		return Result{}, fmt.Errorf("needs %d or more values", HandSize)
+	}
+
+	var probabilities = distinctAfter(HandSize, s.Categories)
+	var categories = []gof.Category{}
+	for r := 1; r <= min(HandSize, s.Categories); r++ {
+		categories = append(categories, gof.Category{Lower: float64(r), Upper: float64(r), Expected: float64(hands) * probabilities[r]})
+	}
+
+	for h := 0; h < hands; h++ {
+		var seen = map[int]bool{}
+		for _, d := range s.Digits[h*HandSize : (h+1)*HandSize] {
+			seen[d] = true
+		}
+		categories[len(seen)-1].Observed++
+	}
+
+	return chiSquared("Poker", categories)
+}
+
+// how many digits it takes to see every one of c. the alphabet is cut down to the largest divisor c of
+// the categories no larger than CouponAlphabet, digit y becoming y / (categories / c)
+func CouponCollector(s Stream) (Result, error) {
+	var c = 0
+	for k := min(CouponAlphabet, s.Categories); k >= 2; k-- {
+		if s.Categories%k == 0 {
+			c = k
+			break
+		}
+	}
+	if c == 0 {
+		return Result{}, fmt.Errorf("%d categories have no divisor from 2 to %d", s.Categories, CouponAlphabet)
+	}
+	var width = s.Categories / c
+
+	var segments = []int{}
+	var seen = map[int]bool{}
+	var length = 0
+	for _, d := range s.Digits {
+		seen[d/width] = true
+		length++
+		if len(seen) == c {
+			segments = append(segments, length)
+			seen, length = map[int]bool{}, 0
+		}
+	}
+	if len(segments) == 0 {
+		return Result{}, errors.New("no segment completed")
+	}
+
+	// q[j] is the probability of j different digits so far without having collected all of them
+	var total = float64(len(segments))
+	var q = make([]float64, c)
+	q[0] = 1
+	var categories = []gof.Category{}
+	var remaining = 1.0
+	for r := 1; r < 10000; r++ {
+		var done = q[c-1] / float64(c)
+		for j := c - 1; j >= 1; j-- {
+			q[j] = q[j]*float64(j)/float64(c) + q[j-1]*float64(c-j+1)/float64(c)
+		}
+		q[0] = 0
+		if r < c {
+			continue
+		}
+
+		if total*remaining < 5 {
+			break
+		}
+		categories = append(categories, gof.Category{Lower: float64(r), Upper: float64(r), Expected: total * done})
+		remaining -= done
+	}
+	categories = append(categories, gof.Category{Lower: float64(c + len(categories)), Upper: math.Inf(1), Expected: total * math.Max(0, remaining)})
+
+	for _, length := range segments {
+		categories[min(length-c, len(categories)-1)].Observed++
+	}
+
+	return chiSquared("Coupon collector", categories)
+}
+
+// marsaglia's birthday spacings: m birthdays in a year of n days, each made of successive digits, sorted.
+// the number of spacings (the last one around the year's end) equal to an earlier one is about poisson with
+// mean m³ / 4n. m is chosen for a mean near one per year and the repeats of all years are added up
+func BirthdaySpacings(s Stream) (Result, error) {
+	var digits = 1
+	var days = float64(s.Categories)
+	for days < YearDays {
+		days *= float64(s.Categories)
+		digits++
+	}
+	if days > 1<<53 {
+		return Result{}, errors.New("the year is too long to count exactly")
+	}
+
+	var m = int(math.Round(math.Cbrt(4 * days)))
+	var years = s.N() / (m * digits)
+	var lambda = float64(years) * float64(m) * float64(m) * float64(m) / (4 * days)
+	if lambda < 5 {
+		return Result{}, fmt.Errorf("needs about %d or more values", int(math.Ceil(5/lambda*float64(s.N()))))
+	}
+
+	var repeats = 0
+	var next = 0
+	for y := 0; y < years; y++ {
+		var birthdays = make([]float64, m)
+		for i := range birthdays {
+			for k := 0; k < digits; k++ {
+				birthdays[i] = birthdays[i]*float64(s.Categories) + float64(s.Digits[next])
+				next++
+			}
+		}
+		sort.Float64s(birthdays)
+
+		var spacings = make([]float64, m)
+		for i := 1; i < m; i++ {
+			spacings[i-1] = birthdays[i] - birthdays[i-1]
+		}
+		spacings[m-1] = birthdays[0] + days - birthdays[m-1]
+		sort.Float64s(spacings)
+
+		for i := 1; i < m; i++ {
+			if spacings[i] == spacings[i-1] {
+				repeats++
+			}
+		}
+	}
+
+	var poisson = distribution.Poisson{Lambda: lambda}
+	return Result{
+		Name:      "Birthday spacings",
+		Reference: fmt.Sprintf("Poisson(%.2f)", lambda),
+		Statistic: float64(repeats),
+		PValue:    math.Min(1, 2*math.Min(poisson.CDF(repeats), poisson.Survival(repeats))),
+		Note:      fmt.Sprintf("%d years of %d birthdays", years, m),
+	}, nil
+}
+
+// pearson's test of the cells, neighbours merged while less than five are expected
+func chiSquared(name string, categories []gof.Category) (Result, error) {
+	result, err := gof.Test(categories, 0, gof.ExpectedMerging(gof.DefaultMinimum))
+	if err != nil {
+		return Result{}, errors.New("too few values for two cells")
+	}
+
+	return Result{
+		Name:      name,
+		Reference: fmt.Sprintf("χ²(%d)", result.DF),
+		Statistic: result.Statistic,
+		PValue:    result.PValue,
+	}, nil
+}
+
+// probabilities of 0..draws different digits among draws drawn uniformly from categories
+func distinctAfter(draws, categories int) []float64 {
+	var q = make([]float64, draws+1)
+	q[0] = 1
+	for i := 0; i < draws; i++ {
+		for j := draws; j >= 1; j-- {
+			q[j] = q[j]*float64(j)/float64(categories) + q[j-1]*float64(categories-j+1)/float64(categories)
+		}
+		q[0] = 0
+	}
+	return q
+}
@@ -0,0 +1,85 @@
+package shared
+
+import "sort"
+
+// how tied values share ranks
+type Ties int
+
+const (
+	// mean of the positions the ties occupy, 1 2.5 2.5 4
+	Average Ties = iota
+	// lowest position, 1 2 2 4
+	Min
+	// highest position, 1 3 3 4
+	Max
+	// consecutive ranks without gaps, 1 2 2 3
+	Dense
+)
+
+// 1-based ranks of values in their original order
+func Ranks(values []float64, ties Ties) []float64 {
+	var order = make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+
+	var ranks = make([]float64, len(values))
+	var dense = 0.0
+
+	for start := 0; start < len(order); {
+		var end = start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		dense++
+
+		var rank float64
+		switch ties {
+		case Min:
+			rank = float64(start + 1)
+		case Max:
+			rank = float64(end)
+		case Dense:
+			rank = dense
+		default:
+			rank = float64(start+1+end) / 2
+		}
+
+		for i := start; i < end; i++ {
+			ranks[order[i]] = rank
+		}
+		start = end
+	}
+
+	return ranks
+}
+
+// sizes of every group of tied values, singletons are left out
+func TieSizes(values []float64) []int {
+	var counts = map[float64]int{}
+	for _, v := range values {
+		counts[v]++
+	}
+
+	var sizes = []int{}
+	for _, c := range counts {
+		if c > 1 {
+			sizes = append(sizes, c)
+		}
+	}
+	sort.Ints(sizes)
+
+	return sizes
+}
+
+// Σ (t³ − t) over tie groups, the quantity every rank test tie correction is built from
+func TieCorrection(values []float64) float64 {
+	var sum = 0.0
+	for _, t := range TieSizes(values) {
+		sum += float64(t*t*t - t)
+	}
+	return sum
+}
@@ -0,0 +1,431 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"shared/interfaces"
+
+	distribution "shared/models/Distribution"
+	hypothesis "shared/models/Hypothesis"
+	rank "shared/models/Rank"
+	sample "shared/models/Sample"
+)
+
+// samples below this size get exact null distributions (mann–whitney and wilcoxon only without ties)
+const ExactLimit = 50
+
+// kruskal–wallis and friedman enumerate every rank assignment up to this many
+const ExactEnumerationLimit = 1_000_000
+
+// Z is the normal approximation (continuity and tie corrected), NaN when the p-value is exact.
+// DF is the chi-squared degrees of freedom of kruskal–wallis and friedman
+type Result struct {
+	Statistic   float64
+	Z           float64
+	DF          float64
+	PValue      float64
+	Exact       bool
+	Alternative hypothesis.Alternative
+}
+
+// mann–whitney U for x against y, Greater means x tends to be larger
+func MannWhitney(x, y interfaces.ISample, alternative hypothesis.Alternative) (Result, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	var nx, ny = len(xs), len(ys)
+	if nx == 0 || ny == 0 {
+		return Result{}, errors.New("ranktest: x and y must not be empty")
+	}
+	var combined = append(append([]float64{}, xs...), ys...)
+	var ranks = rank.Ranks(combined, rank.Average)
+
+	var rankSum = 0.0
+	for i := 0; i < nx; i++ {
+		rankSum += ranks[i]
+	}
+	var u = rankSum - float64(nx*(nx+1))/2
+	var ties = rank.TieCorrection(combined)
+
+	if nx < ExactLimit && ny < ExactLimit && ties == 0 {
+		var counts = subsetSumCounts(nx+ny, nx)
+		// shift rank sums to U
+		var offset = nx * (nx + 1) / 2
+		var pValue = exactPValue(counts[offset:], int(u), alternative)
+		return Result{Statistic: u, Z: math.NaN(), PValue: pValue, Exact: true, Alternative: alternative}, nil
+	}
+
+	var n = float64(nx + ny)
+	var mean = float64(nx*ny) / 2
+	var sigma = math.Sqrt(float64(nx*ny) / 12 * ((n + 1) - ties/(n*(n-1))))
+
+	return normalResult(u, mean, sigma, alternative), nil
+}
+
+// wilcoxon signed-rank test of H0: the distribution of x − mu is symmetric around zero.
+// zero differences are dropped
+func WilcoxonSignedRank(x interfaces.ISample, mu float64, alternative hypothesis.Alternative) (Result, error) {
+	var diffs = []float64{}
+	for _, v := range x.GetValues() {
+		if v != mu {
+			diffs = append(diffs, v-mu)
+		}
+	}
+
+	var n = len(diffs)
+	if n == 0 {
+		return Result{}, errors.New("ranktest: no values differ from mu")
+	}
+	var abs = make([]float64, n)
+	for i, d := range diffs {
+		abs[i] = math.Abs(d)
+	}
+	var ranks = rank.Ranks(abs, rank.Average)
+
+	var v = 0.0
+	for i, d := range diffs {
+		if d > 0 {
+			v += ranks[i]
+		}
+	}
+	var ties = rank.TieCorrection(abs)
+
+	if n < ExactLimit && ties == 0 {
+		var counts = signedRankCounts(n)
+		return Result{Statistic: v, Z: math.NaN(), PValue: exactPValue(counts, int(v), alternative), Exact: true, Alternative: alternative}, nil
+	}
+
+	var nf = float64(n)
+	var mean = nf * (nf + 1) / 4
+	var sigma = math.Sqrt(nf*(nf+1)*(2*nf+1)/24 - ties/48)
+
+	return normalResult(v, mean, sigma, alternative), nil
+}
+
+// wilcoxon signed-rank test on the paired differences x − y
+func WilcoxonPaired(x, y interfaces.ISample, alternative hypothesis.Alternative) (Result, error) {
+	diff, err := differences(x, y)
+	if err != nil {
+		return Result{}, err
+	}
+	return WilcoxonSignedRank(diff, 0, alternative)
+}
+
+// sign test of H0: median(x) = mu, the statistic is the number of values above mu (ties with mu dropped)
+func SignTest(x interfaces.ISample, mu float64, alternative hypothesis.Alternative) (Result, error) {
+	var above, n = 0, 0
+	for _, v := range x.GetValues() {
+		if v == mu {
+			continue
+		}
+		n++
+		if v > mu {
+			above++
+		}
+	}
+	if n == 0 {
+		return Result{}, errors.New("ranktest: no values differ from mu")
+	}
+
+	if n < ExactLimit {
+		var binomial = distribution.Binomial{N: n, P: 0.5}
+		var pValue float64
+		switch alternative {
+		case hypothesis.Greater:
+			pValue = binomial.Survival(above)
+		case hypothesis.Less:
+			pValue = binomial.CDF(above)
+		default:
+			pValue = math.Min(1, 2*math.Min(binomial.CDF(above), binomial.Survival(above)))
+		}
+		return Result{Statistic: float64(above), Z: math.NaN(), PValue: pValue, Exact: true, Alternative: alternative}, nil
+	}
+
+	return normalResult(float64(above), float64(n)/2, math.Sqrt(float64(n))/2, alternative), nil
+}
+
+// kruskal–wallis H with tie correction
+func KruskalWallis(groups ...interfaces.ISample) (Result, error) {
+	if len(groups) < 2 {
+		return Result{}, errors.New("ranktest: at least two groups are needed")
+	}
+
+	var combined = []float64{}
+	var sizes = make([]int, len(groups))
+	for i, group := range groups {
+		var values = group.GetValues()
+		if len(values) == 0 {
+			return Result{}, fmt.Errorf("ranktest: group %d is empty", i)
+		}
+		combined = append(combined, values...)
+		sizes[i] = len(values)
+	}
+
+	var ranks = rank.Ranks(combined, rank.Average)
+	var n = float64(len(combined))
+	var correction = 1 - rank.TieCorrection(combined)/(n*n*n-n)
+
+	var statistic = func(sums []float64) float64 {
+		var h = 0.0
+		for i, s := range sums {
+			h += s * s / float64(sizes[i])
+		}
+		return (12/(n*(n+1))*h - 3*(n+1)) / correction
+	}
+
+	var sums = make([]float64, len(groups))
+	var offset = 0
+	for i, size := range sizes {
+		for j := 0; j < size; j++ {
+			sums[i] += ranks[offset+j]
+		}
+		offset += size
+	}
+	var h = statistic(sums)
+	var df = float64(len(groups) - 1)
+
+	if distribution.Multinomial(sizes) <= ExactEnumerationLimit {
+		var pValue = groupAssignmentPValue(ranks, sizes, h, statistic)
+		return Result{Statistic: h, Z: math.NaN(), DF: df, PValue: pValue, Exact: true}, nil
+	}
+
+	return Result{Statistic: h, Z: math.NaN(), DF: df, PValue: distribution.ChiSquared{K: df}.Survival(h)}, nil
+}
+
+// friedman test for treatments measured on the same blocks, treatments[j][i] is treatment j in block i
+func Friedman(treatments ...interfaces.ISample) (Result, error) {
+	var k = len(treatments)
+	if k < 2 {
+		return Result{}, errors.New("ranktest: at least two treatments are needed")
+	}
+
+	var columns = make([][]float64, k)
+	for j, t := range treatments {
+		columns[j] = t.GetValues()
+		if len(columns[j]) != len(columns[0]) {
+			return Result{}, fmt.Errorf("ranktest: treatment %d has %d blocks, treatment 0 has %d", j, len(columns[j]), len(columns[0]))
+		}
+	}
+	var n = len(columns[0])
+	if n == 0 {
+		return Result{}, errors.New("ranktest: no blocks")
+	}
+
+	// rank inside every block
+	var blockRanks = make([][]float64, n)
+	var ties = 0.0
+	for i := 0; i < n; i++ {
+		var block = make([]float64, k)
+		for j := 0; j < k; j++ {
+			block[j] = columns[j][i]
+		}
+		blockRanks[i] = rank.Ranks(block, rank.Average)
+		ties += rank.TieCorrection(block)
+	}
+
+	var nf, kf = float64(n), float64(k)
+	var correction = 1 - ties/(nf*(kf*kf*kf-kf))
+
+	var statistic = func(sums []float64) float64 {
+		var q = 0.0
+		for _, s := range sums {
+			q += s * s
+		}
+		return (12/(nf*kf*(kf+1))*q - 3*nf*(kf+1)) / correction
+	}
+
+	var sums = make([]float64, k)
+	for _, ranks := range blockRanks {
+		for j, r := range ranks {
+			sums[j] += r
+		}
+	}
+	var q = statistic(sums)
+	var df = kf - 1
+
+	if math.Pow(distribution.Factorial(k), nf) <= ExactEnumerationLimit {
+		var pValue = blockPermutationPValue(blockRanks, q, statistic)
+		return Result{Statistic: q, Z: math.NaN(), DF: df, PValue: pValue, Exact: true}, nil
+	}
+
+	return Result{Statistic: q, Z: math.NaN(), DF: df, PValue: distribution.ChiSquared{K: df}.Survival(q)}, nil
+}
+
+func differences(x, y interfaces.ISample) (interfaces.ISample, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if len(xs) != len(ys) {
+		return nil, fmt.Errorf("ranktest: x and y must have the same length, got %d and %d", len(xs), len(ys))
+	}
+
+	var diff = make(sample.Sample, len(xs))
+	for i := range diff {
+		diff[i] = xs[i] - ys[i]
+	}
+	return diff, nil
+}
+
+func normalResult(statistic, mean, sigma float64, alternative hypothesis.Alternative) Result {
+	var correction = 0.5
+	switch alternative {
+	case hypothesis.Less:
+		correction = -0.5
+	case hypothesis.TwoSided:
+		if statistic < mean {
+			correction = -0.5
+		} else if statistic == mean {
+			correction = 0
+		}
+	}
+
+	var z = (statistic - mean - correction) / sigma
+	var normal = distribution.StandardNormal()
+
+	return Result{
+		Statistic:   statistic,
+		Z:           z,
+		PValue:      hypothesis.PValue(normal.CDF, z, alternative),
+		Alternative: alternative,
+	}
+}
+
+// p-value of an integer statistic from the counts of its null distribution
+func exactPValue(counts []float64, observed int, alternative hypothesis.Alternative) float64 {
+	var total, below, above = 0.0, 0.0, 0.0
+	for s, c := range counts {
+		total += c
+		if s <= observed {
+			below += c
+		}
+		if s >= observed {
+			above += c
+		}
+	}
+
+	switch alternative {
+	case hypothesis.Greater:
+		return above / total
+	case hypothesis.Less:
+		return below / total
+	default:
+		return math.Min(1, 2*math.Min(below, above)/total)
+	}
+}
+
+// counts[s] = number of size-k subsets of {1..n} summing to s
+func subsetSumCounts(n, k int) []float64 {
+	var maxSum = n * (n + 1) / 2
+	var dp = make([][]float64, k+1)
+	for i := range dp {
+		dp[i] = make([]float64, maxSum+1)
+	}
+	dp[0][0] = 1
+
+	for r := 1; r <= n; r++ {
+		for size := min(r, k); size >= 1; size-- {
+			for s := maxSum; s >= r; s-- {
+				dp[size][s] += dp[size-1][s-r]
+			}
+		}
+	}
+
+	return dp[k]
+}
+
+// counts[s] = number of sign assignments of ranks 1..n whose positive ranks sum to s
+func signedRankCounts(n int) []float64 {
+	var maxSum = n * (n + 1) / 2
+	var counts = make([]float64, maxSum+1)
+	counts[0] = 1
+
+	for r := 1; r <= n; r++ {
+		for s := maxSum; s >= r; s-- {
+			counts[s] += counts[s-r]
+		}
+	}
+
+	return counts
+}
+
+// share of all assignments of the pooled ranks to groups of the given sizes with a statistic at least the observed one
+func groupAssignmentPValue(ranks []float64, sizes []int, observed float64, statistic func([]float64) float64) float64 {
+	var sums = make([]float64, len(sizes))
+	var left = append([]int{}, sizes...)
+	var extreme, total = 0.0, 0.0
+	var tolerance = 1e-9 * math.Max(1, math.Abs(observed))
+
+	var assign func(i int)
+	assign = func(i int) {
+		if i == len(ranks) {
+			total++
+			if statistic(sums) >= observed-tolerance {
+				extreme++
+			}
+			return
+		}
+
+		for g := range sizes {
+			if left[g] == 0 {
+				continue
+			}
+			left[g]--
+			sums[g] += ranks[i]
+			assign(i + 1)
+			sums[g] -= ranks[i]
+			left[g]++
+		}
+	}
+	assign(0)
+
+	return extreme / total
+}
+
+// share of all within-block permutations with a statistic at least the observed one
+func blockPermutationPValue(blockRanks [][]float64, observed float64, statistic func([]float64) float64) float64 {
+	var k = len(blockRanks[0])
+	var perms = permutations(k)
+	var sums = make([]float64, k)
+	var extreme, total = 0.0, 0.0
+	var tolerance = 1e-9 * math.Max(1, math.Abs(observed))
+
+	var walk func(block int)
+	walk = func(block int) {
+		if block == len(blockRanks) {
+			total++
+			if statistic(sums) >= observed-tolerance {
+				extreme++
+			}
+			return
+		}
+
+		for _, perm := range perms {
+			for j, p := range perm {
+				sums[j] += blockRanks[block][p]
+			}
+			walk(block + 1)
+			for j, p := range perm {
+				sums[j] -= blockRanks[block][p]
+			}
+		}
+	}
+	walk(0)
+
+	return extreme / total
+}
+
+func permutations(k int) [][]int {
+	if k == 0 {
+		return [][]int{{}}
+	}
+
+	var result = [][]int{}
+	for _, perm := range permutations(k - 1) {
+		for pos := 0; pos <= len(perm); pos++ {
+			var next = make([]int, 0, k)
+			next = append(next, perm[:pos]...)
+			next = append(next, k-1)
+			next = append(next, perm[pos:]...)
+			result = append(result, next)
+		}
+	}
+
+	return result
+}
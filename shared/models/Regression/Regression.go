@@ -90,3 +90,13 @@ func (r *Regression) calculateLinearEquationCoeficients() {
 	r.c = r.cor_coef * r.s_x / r.s_y
 	r.d = r.c_x - r.c*r.c_y
 }
+
+// valid after CalculateRegresionEquations
+func (r *Regression) GetCorrelation() float32 {
+	return r.cor_coef
+}
+
+// slope of the y->x line, valid after CalculateRegresionEquations
+func (r *Regression) GetSlope() float32 {
+	return r.a
+}
@@ -0,0 +1,367 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"shared/interfaces"
+	"sort"
+	"text/tabwriter"
+
+	distribution "shared/models/Distribution"
+	regression "shared/models/Regression"
+	sample "shared/models/Sample"
+	sq "shared/models/Sequence"
+	simulation "shared/models/Simulation"
+)
+
+// statistic over one (re)sample. items are resampled whole, so a point keeps its x and y together.
+// a non-finite result marks a resample the statistic is undefined on, it is left out
+type Statistic[T any] func(data []T) float64
+
+type Method int
+
+const (
+	Percentile Method = iota
+	Basic
+	BCa
+	Studentized
+)
+
+func (m Method) String() string {
+	switch m {
+	case Basic:
+		return "basic"
+	case BCa:
+		return "BCa"
+	case Studentized:
+		return "studentized"
+	default:
+		return "percentile"
+	}
+}
+
+type Interval struct {
+	Method     Method
+	Lower      float64
+	Upper      float64
+	Confidence float64
+}
+
+type JackknifeResult struct {
+	Estimate float64
+	// leave-one-out estimates, Values[i] omits item i
+	Values    []float64
+	Bias      float64
+	StdError  float64
+	Corrected float64
+}
+
+type BootstrapResult struct {
+	Estimate float64
+	// statistic of every usable resample, ascending
+	Replicates []float64
+	Failed     int
+	Bias       float64
+	StdError   float64
+	// studentized pivots (θ* − θ̂) / se*, ascending. only BootstrapStudentized fills them
+	Pivots []float64
+	// standard error of Estimate that scales the pivots back
+	PivotScale float64
+	// leave-one-out estimates behind the BCa acceleration
+	Jackknife JackknifeResult
+}
+
+// nested resamples behind the standard error of every replicate when BootstrapStudentized gets no formula
+const InnerReplications = 50
+
+// leave-one-out estimates of bias and standard error. they need a smooth statistic,
+// for the median the leave-one-out values barely move and the standard error is far too small
+func Jackknife[T any](data []T, statistic Statistic[T]) (JackknifeResult, error) {
+	if len(data) < 2 {
+		return JackknifeResult{}, errors.New("jackknife: at least two items are needed")
+	}
+
+	var n = len(data)
+	var result = JackknifeResult{Estimate: statistic(data), Values: make([]float64, n)}
+	var omitted = make([]T, n-1)
+
+	for i := range data {
+		copy(omitted, data[:i])
+		copy(omitted[i:], data[i+1:])
+		result.Values[i] = statistic(omitted)
+	}
+
+	var mean = sample.Mean(result.Values)
+	var ss = 0.0
+	for _, v := range result.Values {
+		ss += (v - mean) * (v - mean)
+	}
+
+	var m = float64(n)
+	result.Bias = (m - 1) * (mean - result.Estimate)
+	result.StdError = math.Sqrt((m - 1) / m * ss)
+	result.Corrected = result.Estimate - result.Bias
+
+	return result, nil
+}
+
+// resamples data with replacement config.Replications times, replicate i draws from stream i of config.Seed
+func Bootstrap[T any](data []T, statistic Statistic[T], config simulation.Config) (BootstrapResult, error) {
+	if len(data) < 2 {
+		return BootstrapResult{}, errors.New("bootstrap: at least two items are needed")
+	}
+
+	replicates, err := simulation.Replicate(config, func(rnd *rand.Rand) float64 {
+		return statistic(resample(rnd, data))
+	})
+	if err != nil {
+		return BootstrapResult{}, err
+	}
+
+	return summarize(data, statistic, replicates)
+}
+
+// Bootstrap that also collects the pivots of the studentized interval. stdError is the standard error of
+// statistic on one sample, nil estimates it from InnerReplications nested resamples of every replicate
+func BootstrapStudentized[T any](data []T, statistic Statistic[T], stdError Statistic[T], config simulation.Config) (BootstrapResult, error) {
+	if len(data) < 2 {
+		return BootstrapResult{}, errors.New("bootstrap: at least two items are needed")
+	}
+
+	pairs, err := simulation.Replicate(config, func(rnd *rand.Rand) [2]float64 {
+		var draw = resample(rnd, data)
+		if stdError != nil {
+			return [2]float64{statistic(draw), stdError(draw)}
+		}
+		return [2]float64{statistic(draw), nestedStdError(rnd, draw, statistic)}
+	})
+	if err != nil {
+		return BootstrapResult{}, err
+	}
+
+	var replicates = make([]float64, len(pairs))
+	for i, p := range pairs {
+		replicates[i] = p[0]
+	}
+
+	result, err := summarize(data, statistic, replicates)
+	if err != nil {
+		return result, err
+	}
+
+	result.PivotScale = result.StdError
+	if stdError != nil {
+		result.PivotScale = stdError(data)
+	}
+
+	for _, p := range pairs {
+		var pivot = (p[0] - result.Estimate) / p[1]
+		if !math.IsNaN(pivot) && !math.IsInf(pivot, 0) {
+			result.Pivots = append(result.Pivots, pivot)
+		}
+	}
+	sort.Float64s(result.Pivots)
+
+	if len(result.Pivots) == 0 {
+		return result, errors.New("bootstrap: no replicate had a usable standard error")
+	}
+
+	return result, nil
+}
+
+// two-sided interval with the given coverage, e.g. 0.95
+func (r BootstrapResult) Interval(method Method, confidence float64) (Interval, error) {
+	var alpha = (1 - confidence) / 2
+	var interval = Interval{Method: method, Confidence: confidence}
+
+	switch method {
+	case Percentile:
+		interval.Lower = quantile(r.Replicates, alpha)
+		interval.Upper = quantile(r.Replicates, 1-alpha)
+
+	case Basic:
+		interval.Lower = 2*r.Estimate - quantile(r.Replicates, 1-alpha)
+		interval.Upper = 2*r.Estimate - quantile(r.Replicates, alpha)
+
+	case BCa:
+		var z0, a = r.biasCorrection(), r.acceleration()
+		var normal = distribution.StandardNormal()
+		var adjust = func(p float64) float64 {
+			var z = z0 + normal.Quantile(p)
+			return normal.CDF(z0 + z/(1-a*z))
+		}
+		interval.Lower = quantile(r.Replicates, adjust(alpha))
+		interval.Upper = quantile(r.Replicates, adjust(1-alpha))
+
+	case Studentized:
+		if len(r.Pivots) == 0 {
+			return interval, errors.New("bootstrap: studentized intervals need BootstrapStudentized")
+		}
+		interval.Lower = r.Estimate - quantile(r.Pivots, 1-alpha)*r.PivotScale
+		interval.Upper = r.Estimate - quantile(r.Pivots, alpha)*r.PivotScale
+
+	default:
+		return interval, fmt.Errorf("bootstrap: unknown interval method %d", method)
+	}
+
+	return interval, nil
+}
+
+// every interval the result supports
+func (r BootstrapResult) Intervals(confidence float64) []Interval {
+	var intervals = []Interval{}
+	for _, method := range []Method{Percentile, Basic, BCa, Studentized} {
+		if interval, err := r.Interval(method, confidence); err == nil {
+			intervals = append(intervals, interval)
+		}
+	}
+	return intervals
+}
+
+func (r BootstrapResult) WriteTable(w io.Writer, confidence float64) error {
+	fmt.Fprintf(w, "Estimate: %.4f, bias: %.4f, std. error: %.4f (%d resamples, %d failed)\n", r.Estimate, r.Bias, r.StdError, len(r.Replicates), r.Failed)
+	fmt.Fprintf(w, "Jackknife bias: %.4f, std. error: %.4f\n", r.Jackknife.Bias, r.Jackknife.StdError)
+
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintf(writer, " %g%% interval\t Lower\t Upper\t\n", 100*confidence)
+	for _, interval := range r.Intervals(confidence) {
+		fmt.Fprintf(writer, " %s\t %.4f\t %.4f\t\n", interval.Method, interval.Lower, interval.Upper)
+	}
+
+	return writer.Flush()
+}
+
+// Sequence.GetAverage over resampled source values
+func SequenceAverage(source []int) float64 {
+	return float64(sq.FromSource(source).GetAverage())
+}
+
+// Sequence.GetVariationsMedian over resampled source values, the two middle variations are averaged
+func SequenceMedian(source []int) float64 {
+	var median = sq.FromSource(source).GetVariationsMedian()
+	var sum = 0
+	for _, v := range median {
+		sum += v
+	}
+	return float64(sum) / float64(len(median))
+}
+
+func Mean(values []float64) float64 {
+	return sample.Mean(values)
+}
+
+func Median(values []float64) float64 {
+	return sample.Median(values)
+}
+
+// slope of the y->x regression line
+func Slope(points []interfaces.IPoint) float64 {
+	var r = regression.Make(points)
+	r.CalculateRegresionEquations()
+	return float64(r.GetSlope())
+}
+
+func Correlation(points []interfaces.IPoint) float64 {
+	var r = regression.Make(points)
+	r.CalculateRegresionEquations()
+	return float64(r.GetCorrelation())
+}
+
+func resample[T any](rnd *rand.Rand, data []T) []T {
+	var draw = make([]T, len(data))
+	for i := range draw {
+		draw[i] = data[rnd.Intn(len(data))]
+	}
+	return draw
+}
+
+func nestedStdError[T any](rnd *rand.Rand, data []T, statistic Statistic[T]) float64 {
+	var values = []float64{}
+	for b := 0; b < InnerReplications; b++ {
+		var v = statistic(resample(rnd, data))
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			values = append(values, v)
+		}
+	}
+	if len(values) < 2 {
+		return math.NaN()
+	}
+	return sample.StdDev(values)
+}
+
+func summarize[T any](data []T, statistic Statistic[T], replicates []float64) (BootstrapResult, error) {
+	var result = BootstrapResult{Estimate: statistic(data)}
+
+	for _, v := range replicates {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			result.Failed++
+			continue
+		}
+		result.Replicates = append(result.Replicates, v)
+	}
+	sort.Float64s(result.Replicates)
+
+	if len(result.Replicates) < 2 {
+		return result, errors.New("bootstrap: fewer than two resamples gave a usable statistic")
+	}
+
+	result.Bias = sample.Mean(result.Replicates) - result.Estimate
+	result.StdError = sample.StdDev(result.Replicates)
+	jackknife, err := Jackknife(data, statistic)
+	if err != nil {
+		return result, err
+	}
+	result.Jackknife = jackknife
+
+	return result, nil
+}
+
+// z0 = Φ⁻¹(share of replicates below the estimate). ties count half, they pile up for discrete
+// statistics like the median. kept finite when every replicate is on one side
+func (r BootstrapResult) biasCorrection() float64 {
+	var below = sort.SearchFloat64s(r.Replicates, r.Estimate)
+	var ties = 0
+	for i := below; i < len(r.Replicates) && r.Replicates[i] == r.Estimate; i++ {
+		ties++
+	}
+
+	var b = float64(len(r.Replicates))
+	var share = math.Min(math.Max((float64(below)+float64(ties)/2)/b, 0.5/b), 1-0.5/b)
+	return distribution.StandardNormal().Quantile(share)
+}
+
+// a = Σ d³ / (6 (Σ d²)^(3/2)) over the jackknife deviations d = mean − θ(i)
+func (r BootstrapResult) acceleration() float64 {
+	var mean = sample.Mean(r.Jackknife.Values)
+	var squares, cubes = 0.0, 0.0
+	for _, v := range r.Jackknife.Values {
+		var d = mean - v
+		squares += d * d
+		cubes += d * d * d
+	}
+	if squares == 0 {
+		return 0
+	}
+	return cubes / (6 * math.Pow(squares, 1.5))
+}
+
+// order statistic at p·(B + 1), interpolated between neighbours and clamped to the sample
+func quantile(sorted []float64, p float64) float64 {
+	var position = p*float64(len(sorted)+1) - 1
+	if position <= 0 {
+		return sorted[0]
+	}
+	if position >= float64(len(sorted)-1) {
+		return sorted[len(sorted)-1]
+	}
+
+	var i = int(position)
+	var w = position - float64(i)
+	return (1-w)*sorted[i] + w*sorted[i+1]
+}
@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"math"
+	"sort"
+)
+
+// plain float observations, satisfies interfaces.ISample
+type Sample []float64
+
+func (s Sample) GetValues() []float64 {
+	return s
+}
+
+func FromFloat32(values []float32) Sample {
+	var sample = make(Sample, len(values))
+	for i, v := range values {
+		sample[i] = float64(v)
+	}
+	return sample
+}
+
+func FromInts(values []int) Sample {
+	var sample = make(Sample, len(values))
+	for i, v := range values {
+		sample[i] = float64(v)
+	}
+	return sample
+}
+
+func Mean(values []float64) float64 {
+	var sum = 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// unbiased (n-1) variance
+func Variance(values []float64) float64 {
+	var mean = Mean(values)
+	var ss = 0.0
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return ss / float64(len(values)-1)
+}
+
+func StdDev(values []float64) float64 {
+	return math.Sqrt(Variance(values))
+}
+
+func Sorted(values []float64) []float64 {
+	var sorted = make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+func Median(values []float64) float64 {
+	var sorted = Sorted(values)
+	var mid = len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// linear interpolation between order statistics (R's type 7)
+func Quantile(values []float64, p float64) float64 {
+	var sorted = Sorted(values)
+	var position = p * float64(len(sorted)-1)
+	var i = int(position)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	var w = position - float64(i)
+	return (1-w)*sorted[i] + w*sorted[i+1]
+}
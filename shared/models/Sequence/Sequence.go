@@ -30,16 +30,32 @@ func Reduce[T any, U any](slice []T, reducer reducerFn[T, U], initialValue U) U
 
 ////
 
+// n values drawn uniformly from low..high, both ends included
 func Random(n int, low int, high int) Sequence {
 	var sequence []int = []int{}
-	var variations []int = []int{}
 
 	for i := 0; i < n; i++ {
-		var rndVal = rand.Intn(high) + low
+		var rndVal = rand.Intn(high-low+1) + low
 		sequence = append(sequence, rndVal)
 	}
 
-	variations = make([]int, len(sequence))
+	return FromSource(sequence)
+}
+
+// same as Random, but draws from the given source so the sequence can be reproduced
+func RandomFrom(rnd *rand.Rand, n int, low int, high int) Sequence {
+	var sequence []int = []int{}
+
+	for i := 0; i < n; i++ {
+		var rndVal = rnd.Intn(high-low+1) + low
+		sequence = append(sequence, rndVal)
+	}
+
+	return FromSource(sequence)
+}
+
+func FromSource(sequence []int) Sequence {
+	var variations = make([]int, len(sequence))
 	copy(variations, sequence)
 
 	sort.Slice(variations, func(i, j int) bool {
@@ -53,6 +69,15 @@ func (s Sequence) GetVariations() []int {
 	return s.Variations
 }
 
+// source values in drawing order, satisfies interfaces.ISample
+func (s Sequence) GetValues() []float64 {
+	var values = make([]float64, len(s.Source))
+	for i, v := range s.Source {
+		values[i] = float64(v)
+	}
+	return values
+}
+
 func (s Sequence) GetVariationsMedian() []int {
 	var midIdx = s.Variations_length / 2
 	var median = []int{}
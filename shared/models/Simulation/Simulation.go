@@ -0,0 +1,279 @@
+package shared
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+
+	stream_pkg "shared/models/RandomStream"
+)
+
+// one estimate produced by a replicate. Lower/Upper describe a confidence interval, leave HasInterval false when there is none
+type Estimate struct {
+	Name        string
+	Truth       float64
+	Value       float64
+	Lower       float64
+	Upper       float64
+	HasInterval bool
+}
+
+// NullTrue marks replicates generated under H0, their rejection rate is the empirical size, otherwise it is power
+type Decision struct {
+	Name     string
+	Rejected bool
+	NullTrue bool
+}
+
+type Outcome struct {
+	Estimates []Estimate
+	Decisions []Decision
+}
+
+// one generate -> estimate round. rnd is the replicate's own stream and must be the only source of randomness
+type Experiment func(rnd *rand.Rand) Outcome
+
+type Config struct {
+	Replications int
+	// 0 means runtime.NumCPU()
+	Workers int
+	Seed    int64
+}
+
+type EstimatorSummary struct {
+	Name         string
+	Truth        float64
+	Replications int
+	Mean         float64
+	Bias         float64
+	Variance     float64
+	MSE          float64
+	// monte carlo standard error of Mean
+	MCSE float64
+	// share of intervals covering Truth, NaN when the estimator reported no intervals
+	Coverage  float64
+	Intervals int
+}
+
+type TestSummary struct {
+	Name          string
+	NullTrue      bool
+	Replications  int
+	Rejections    int
+	RejectionRate float64
+	// monte carlo standard error of RejectionRate
+	MCSE float64
+}
+
+type Report struct {
+	Config     Config
+	Estimators []EstimatorSummary
+	Tests      []TestSummary
+}
+
+func Run(config Config, experiment Experiment) (Report, error) {
+	outcomes, err := Replicate(config, experiment)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return summarize(config, outcomes), nil
+}
+
+// raw values of a statistic over the replicates, in replicate order. seeding matches Run
+func Replicate[T any](config Config, statistic func(rnd *rand.Rand) T) ([]T, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	var values = make([]T, config.Replications)
+	parallel(config, func(i int, rnd *rand.Rand) {
+		values[i] = statistic(rnd)
+	})
+
+	return values, nil
+}
+
+func (c Config) validate() error {
+	if c.Replications < 0 {
+		return fmt.Errorf("simulation: replications must not be negative, got %d", c.Replications)
+	}
+	return nil
+}
+
+// replicate i always gets stream i, whichever worker picks it up
+func parallel(config Config, replicate func(i int, rnd *rand.Rand)) {
+	var workers = config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var jobs = make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				replicate(i, stream_pkg.Make(config.Seed, i))
+			}
+		}()
+	}
+
+	for i := 0; i < config.Replications; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// aggregation keeps the order in which names first appear in the outcomes
+func summarize(config Config, outcomes []Outcome) Report {
+	var estimates = map[string][]Estimate{}
+	var estimateOrder = []string{}
+	var decisions = map[string][]Decision{}
+	var decisionOrder = []string{}
+
+	for _, outcome := range outcomes {
+		for _, e := range outcome.Estimates {
+			if _, ok := estimates[e.Name]; !ok {
+				estimateOrder = append(estimateOrder, e.Name)
+			}
+			estimates[e.Name] = append(estimates[e.Name], e)
+		}
+
+		for _, d := range outcome.Decisions {
+			var key = decisionKey(d)
+			if _, ok := decisions[key]; !ok {
+				decisionOrder = append(decisionOrder, key)
+			}
+			decisions[key] = append(decisions[key], d)
+		}
+	}
+
+	var report = Report{Config: config}
+
+	for _, name := range estimateOrder {
+		report.Estimators = append(report.Estimators, summarizeEstimates(name, estimates[name]))
+	}
+
+	for _, key := range decisionOrder {
+		report.Tests = append(report.Tests, summarizeDecisions(decisions[key]))
+	}
+
+	return report
+}
+
+func decisionKey(d Decision) string {
+	if d.NullTrue {
+		return d.Name + "\x00size"
+	}
+	return d.Name + "\x00power"
+}
+
+func summarizeEstimates(name string, estimates []Estimate) EstimatorSummary {
+	var n = float64(len(estimates))
+	var truth = estimates[0].Truth
+	var mean, sqErr = 0.0, 0.0
+	var covered, intervals = 0, 0
+
+	for _, e := range estimates {
+		mean += e.Value
+		sqErr += (e.Value - truth) * (e.Value - truth)
+
+		if e.HasInterval {
+			intervals++
+			if e.Lower <= truth && truth <= e.Upper {
+				covered++
+			}
+		}
+	}
+	mean /= n
+
+	var variance = 0.0
+	for _, e := range estimates {
+		variance += (e.Value - mean) * (e.Value - mean)
+	}
+	if len(estimates) > 1 {
+		variance /= n - 1
+	}
+
+	var coverage = math.NaN()
+	if intervals > 0 {
+		coverage = float64(covered) / float64(intervals)
+	}
+
+	return EstimatorSummary{
+		Name:         name,
+		Truth:        truth,
+		Replications: len(estimates),
+		Mean:         mean,
+		Bias:         mean - truth,
+		Variance:     variance,
+		MSE:          sqErr / n,
+		MCSE:         math.Sqrt(variance / n),
+		Coverage:     coverage,
+		Intervals:    intervals,
+	}
+}
+
+func summarizeDecisions(decisions []Decision) TestSummary {
+	var rejections = 0
+	for _, d := range decisions {
+		if d.Rejected {
+			rejections++
+		}
+	}
+
+	var n = float64(len(decisions))
+	var rate = float64(rejections) / n
+
+	return TestSummary{
+		Name:          decisions[0].Name,
+		NullTrue:      decisions[0].NullTrue,
+		Replications:  len(decisions),
+		Rejections:    rejections,
+		RejectionRate: rate,
+		MCSE:          math.Sqrt(rate * (1 - rate) / n),
+	}
+}
+
+func (r Report) WriteTable(w io.Writer) error {
+	const paddingAmount = 3
+	const paddingChar = ' '
+	writer := tabwriter.NewWriter(w, 0, 0, paddingAmount, paddingChar, tabwriter.Debug)
+
+	fmt.Fprintf(writer, "Replications: %d, seed: %d\n\n", r.Config.Replications, r.Config.Seed)
+
+	if len(r.Estimators) > 0 {
+		fmt.Fprintln(writer, " Estimator\t Truth\t Mean\t Bias\t Variance\t MSE\t MCSE\t Coverage\t")
+		for _, e := range r.Estimators {
+			var coverage = "-"
+			if !math.IsNaN(e.Coverage) {
+				coverage = fmt.Sprintf("%.4f", e.Coverage)
+			}
+			fmt.Fprintf(writer, " %s\t %.4g\t %.4g\t %.4g\t %.4g\t %.4g\t %.2g\t %s\t\n",
+				e.Name, e.Truth, e.Mean, e.Bias, e.Variance, e.MSE, e.MCSE, coverage)
+		}
+		fmt.Fprintln(writer)
+	}
+
+	if len(r.Tests) > 0 {
+		fmt.Fprintln(writer, " Test\t Under\t Measures\t Rejections\t Rate\t MCSE\t")
+		for _, t := range r.Tests {
+			var under, measures = "H1", "power"
+			if t.NullTrue {
+				under, measures = "H0", "size"
+			}
+			fmt.Fprintf(writer, " %s\t %s\t %s\t %d/%d\t %.4f\t %.2g\t\n",
+				t.Name, under, measures, t.Rejections, t.Replications, t.RejectionRate, t.MCSE)
+		}
+	}
+
+	return writer.Flush()
+}
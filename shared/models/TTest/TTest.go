@@ -0,0 +1,206 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"shared/interfaces"
+
+	distribution "shared/models/Distribution"
+	hypothesis "shared/models/Hypothesis"
+	sample "shared/models/Sample"
+)
+
+// Estimate is the mean difference under test: x̄ − μ0, x̄ − ȳ or the mean of paired differences.
+// Lower/Upper is its confidence interval, one-sided alternatives leave one bound infinite.
+// DF is +Inf for z-tests
+type Result struct {
+	Statistic   float64
+	DF          float64
+	PValue      float64
+	Alternative hypothesis.Alternative
+	Estimate    float64
+	StdError    float64
+	Lower       float64
+	Upper       float64
+	Confidence  float64
+	CohenD      float64
+}
+
+type referenceDistribution interface {
+	CDF(x float64) float64
+	Quantile(p float64) float64
+}
+
+// one-sample t-test of H0: μ = mu0
+func OneSample(x interfaces.ISample, mu0 float64, alternative hypothesis.Alternative, confidence float64) (Result, error) {
+	var values = x.GetValues()
+	if err := atLeast("x", values, 2); err != nil {
+		return Result{}, err
+	}
+
+	var n = float64(len(values))
+	var sd = sample.StdDev(values)
+	var diff = sample.Mean(values) - mu0
+
+	var result = complete(distribution.StudentT{Nu: n - 1}, diff, sd/math.Sqrt(n), alternative, confidence)
+	result.DF = n - 1
+	result.CohenD = diff / sd
+
+	return result, nil
+}
+
+// paired t-test of H0: mean(x − y) = 0, x and y are matched by position
+func Paired(x, y interfaces.ISample, alternative hypothesis.Alternative, confidence float64) (Result, error) {
+	diff, err := differences(x, y)
+	if err != nil {
+		return Result{}, err
+	}
+	return OneSample(diff, 0, alternative, confidence)
+}
+
+// two-sample t-test assuming equal variances
+func Pooled(x, y interfaces.ISample, alternative hypothesis.Alternative, confidence float64) (Result, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if err := atLeast("x", xs, 1); err != nil {
+		return Result{}, err
+	}
+	if err := atLeast("y", ys, 1); err != nil {
+		return Result{}, err
+	}
+	if len(xs)+len(ys) < 3 {
+		return Result{}, errors.New("ttest: the pooled test needs three or more values in all")
+	}
+
+	var nx, ny = float64(len(xs)), float64(len(ys))
+	var df = nx + ny - 2
+	var pooled = math.Sqrt(((nx-1)*variance(xs) + (ny-1)*variance(ys)) / df)
+	var diff = sample.Mean(xs) - sample.Mean(ys)
+
+	var result = complete(distribution.StudentT{Nu: df}, diff, pooled*math.Sqrt(1/nx+1/ny), alternative, confidence)
+	result.DF = df
+	result.CohenD = diff / pooled
+
+	return result, nil
+}
+
+// two-sample t-test without the equal variance assumption, welch–satterthwaite degrees of freedom.
+// cohen's d uses the root mean square of the two standard deviations
+func Welch(x, y interfaces.ISample, alternative hypothesis.Alternative, confidence float64) (Result, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if err := atLeast("x", xs, 2); err != nil {
+		return Result{}, err
+	}
+	if err := atLeast("y", ys, 2); err != nil {
+		return Result{}, err
+	}
+
+	var nx, ny = float64(len(xs)), float64(len(ys))
+	var vx, vy = sample.Variance(xs), sample.Variance(ys)
+	var qx, qy = vx / nx, vy / ny
+	var df = (qx + qy) * (qx + qy) / (qx*qx/(nx-1) + qy*qy/(ny-1))
+	var diff = sample.Mean(xs) - sample.Mean(ys)
+
+	var result = complete(distribution.StudentT{Nu: df}, diff, math.Sqrt(qx+qy), alternative, confidence)
+	result.DF = df
+	result.CohenD = diff / math.Sqrt((vx+vy)/2)
+
+	return result, nil
+}
+
+// one-sample z-test of H0: μ = mu0 with known standard deviation sigma
+func ZOneSample(x interfaces.ISample, mu0, sigma float64, alternative hypothesis.Alternative, confidence float64) (Result, error) {
+	var values = x.GetValues()
+	if err := atLeast("x", values, 1); err != nil {
+		return Result{}, err
+	}
+	if sigma <= 0 {
+		return Result{}, fmt.Errorf("ttest: the known standard deviation must be positive, got %g", sigma)
+	}
+
+	var diff = sample.Mean(values) - mu0
+
+	var result = complete(distribution.StandardNormal(), diff, sigma/math.Sqrt(float64(len(values))), alternative, confidence)
+	result.DF = math.Inf(1)
+	result.CohenD = diff / sigma
+
+	return result, nil
+}
+
+// two-sample z-test of H0: μx = μy with known standard deviations
+func ZTwoSample(x, y interfaces.ISample, sigmaX, sigmaY float64, alternative hypothesis.Alternative, confidence float64) (Result, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if err := atLeast("x", xs, 1); err != nil {
+		return Result{}, err
+	}
+	if err := atLeast("y", ys, 1); err != nil {
+		return Result{}, err
+	}
+	if sigmaX <= 0 || sigmaY <= 0 {
+		return Result{}, fmt.Errorf("ttest: the known standard deviations must be positive, got %g and %g", sigmaX, sigmaY)
+	}
+
+	var nx, ny = float64(len(xs)), float64(len(ys))
+	var diff = sample.Mean(xs) - sample.Mean(ys)
+
+	var result = complete(distribution.StandardNormal(), diff, math.Sqrt(sigmaX*sigmaX/nx+sigmaY*sigmaY/ny), alternative, confidence)
+	result.DF = math.Inf(1)
+	result.CohenD = diff / math.Sqrt((sigmaX*sigmaX+sigmaY*sigmaY)/2)
+
+	return result, nil
+}
+
+func differences(x, y interfaces.ISample) (sample.Sample, error) {
+	var xs, ys = x.GetValues(), y.GetValues()
+	if len(xs) != len(ys) {
+		return nil, fmt.Errorf("ttest: x and y must have the same length, got %d and %d", len(xs), len(ys))
+	}
+
+	var diff = make(sample.Sample, len(xs))
+	for i := range diff {
+		diff[i] = xs[i] - ys[i]
+	}
+	return diff, nil
+}
+
+func atLeast(name string, values []float64, n int) error {
+	if len(values) < n {
+		return fmt.Errorf("ttest: %s needs at least %d values, got %d", name, n, len(values))
+	}
+	return nil
+}
+
+// sample variance, 0 for a single value, which only the pooled test allows
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	return sample.Variance(values)
+}
+
+// statistic, p-value and interval shared by every test here
+func complete(reference referenceDistribution, estimate, se float64, alternative hypothesis.Alternative, confidence float64) Result {
+	var statistic = estimate / se
+	var lower, upper = math.Inf(-1), math.Inf(1)
+
+	switch alternative {
+	case hypothesis.Greater:
+		lower = estimate - reference.Quantile(confidence)*se
+	case hypothesis.Less:
+		upper = estimate + reference.Quantile(confidence)*se
+	default:
+		var q = reference.Quantile(1 - (1-confidence)/2)
+		lower, upper = estimate-q*se, estimate+q*se
+	}
+
+	return Result{
+		Statistic:   statistic,
+		PValue:      hypothesis.PValue(reference.CDF, statistic, alternative),
+		Alternative: alternative,
+		Estimate:    estimate,
+		StdError:    se,
+		Lower:       lower,
+		Upper:       upper,
+		Confidence:  confidence,
+	}
+}
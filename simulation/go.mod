@@ -0,0 +1,6 @@
+module simulation
+
+go 1.25.1
+
+require shared v0.0.0
+replace shared => ../shared
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	dataset "shared/models/Dataset"
+	point "shared/models/Point"
+	regression "shared/models/Regression"
+	sq "shared/models/Sequence"
+	simulation "shared/models/Simulation"
+)
+
+const (
+	Low  = 1
+	High = 12
+	N    = 12
+	k    = 2.45
+	b    = 3.11
+	// two-sided 5% normal quantile
+	z975 = 1.959963984540054
+)
+
+// Sequence.Random draws low..high uniformly, so the midpoint is the true mean of GetAverage
+func sequenceMean() float64 {
+	return float64(Low+High) / 2
+}
+
+func averageExperiment(rnd *rand.Rand) simulation.Outcome {
+	var sequence = sq.RandomFrom(rnd, N, Low, High)
+	var avg = float64(sequence.GetAverage())
+
+	var ss = 0.0
+	for _, v := range sequence.Source {
+		ss += (float64(v) - avg) * (float64(v) - avg)
+	}
+	var se = math.Sqrt(ss/float64(N-1)) / math.Sqrt(N)
+	var truth = sequenceMean()
+
+	return simulation.Outcome{
+		Estimates: []simulation.Estimate{
+			{Name: "GetAverage (z-interval)", Truth: truth, Value: avg, Lower: avg - z975*se, Upper: avg + z975*se, HasInterval: true},
+		},
+		Decisions: []simulation.Decision{
+			{Name: "z-test on mean", Rejected: math.Abs(avg-truth)/se > z975, NullTrue: true},
+			{Name: "z-test on mean", Rejected: math.Abs(avg-(truth-1))/se > z975, NullTrue: false},
+		},
+	}
+}
+
+func regressionExperiment(rnd *rand.Rand) simulation.Outcome {
+	var spec = dataset.Spec{
+		Rows:  N,
+		Seed:  rnd.Int63(),
+		X:     dataset.XDesign{Kind: dataset.XLinear, Step: 1},
+		Model: dataset.Model{Kind: dataset.ModelLinear, K: k, B: b},
+		Noise: dataset.Noise{Kind: dataset.NoiseNormal, Deviation: float64(N) / 5},
+	}
+
+	data, err := dataset.Generate(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var r = regression.Make(point.PointArrayToIPointArray(data.Points()))
+	var fns = r.CalculateRegresionEquations()
+	var intercept = float64(fns.Y_X(0))
+	var slope = float64(fns.Y_X(1)) - intercept
+
+	return simulation.Outcome{
+		Estimates: []simulation.Estimate{
+			{Name: "Regression slope", Truth: k, Value: slope},
+			{Name: "Regression intercept", Truth: b, Value: intercept},
+		},
+	}
+}
+
+func main() {
+	var replications = flag.Int("n", 2000, "number of replications")
+	var workers = flag.Int("workers", 0, "worker goroutines, 0 uses every cpu")
+	var seed = flag.Int64("seed", 1100, "base seed")
+	flag.Parse()
+
+	var config = simulation.Config{Replications: *replications, Workers: *workers, Seed: *seed}
+
+	report, err := simulation.Run(config, func(rnd *rand.Rand) simulation.Outcome {
+		var outcome = averageExperiment(rnd)
+		var fit = regressionExperiment(rnd)
+		outcome.Estimates = append(outcome.Estimates, fit.Estimates...)
+		return outcome
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := report.WriteTable(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}